@@ -0,0 +1,142 @@
+package namemachine
+
+import (
+	"regexp"
+	"strings"
+)
+
+/**
+ * GenerateAvoiding generates a name redrawing while exists reports it as taken
+ * handy for checking candidates against an external store before handing them out
+ * retries are bounded so a saturated store yields ErrExhausted instead of spinning
+ * @param nWords int optional override for number of words
+ * @param exists func(string) bool reports names already in use
+ * @return string generated name and error if any
+ */
+func (g *Generator) GenerateAvoiding(nWords int, exists func(name string) bool) (string, error) {
+	return g.generateAvoiding(nWords, exists, false)
+}
+
+/**
+ * GenerateAvoidingFold is GenerateAvoiding for stores that ignore case
+ * exists sees the candidate lowercased so Brave-Otter is rejected when brave-otter
+ * is taken build the predicate with FoldedSet or lowercase stored names the same way
+ * the returned name keeps its configured casing
+ * @param nWords int optional override for number of words
+ * @param exists func(string) bool reports lowercased names already in use
+ * @return string generated name and error if any
+ */
+func (g *Generator) GenerateAvoidingFold(nWords int, exists func(lower string) bool) (string, error) {
+	return g.generateAvoiding(nWords, exists, true)
+}
+
+/**
+ * FoldedSet returns a case insensitive membership predicate over names
+ * both the stored names and each candidate are lowercased before comparing
+ * @param names ...string names already in use
+ * @return func(string) bool true when a name matches ignoring case
+ */
+func FoldedSet(names ...string) func(string) bool {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[strings.ToLower(n)] = struct{}{}
+	}
+	return func(name string) bool {
+		_, ok := set[strings.ToLower(name)]
+		return ok
+	}
+}
+
+/**
+ * GenerateMatching generates a name redrawing until the whole name matches re
+ * a general post filter for downstream naming rules the match sees the final
+ * formatted name including slug and counter
+ * @param re *regexp.Regexp pattern the name must match
+ * @param nWords int optional override for number of words
+ * @return string generated name and ErrExhausted when retries ran out
+ */
+func (g *Generator) GenerateMatching(re *regexp.Regexp, nWords int) (string, error) {
+	return g.generateChecked(nWords, func(name []byte) bool { return !re.Match(name) })
+}
+
+/**
+ * generateAvoiding adapts exists to a rejection check
+ * with OnCollision set a taken candidate is handed to the callback first and
+ * only redrawn when the callback returns it unchanged the result is cut to
+ * MaxTotalLen and checked against exists held and ReservedWords like a draw
+ * @param nWords int optional override for number of words
+ * @param exists func(string) bool reports names already in use
+ * @param fold bool lowercase the candidate before asking exists
+ * @return string generated name and error if any
+ */
+func (g *Generator) generateAvoiding(nWords int, exists func(string) bool, fold bool) (string, error) {
+	taken := func(name string) bool {
+		if fold {
+			name = strings.ToLower(name)
+		}
+		return exists(name)
+	}
+	if g.onCollision == nil {
+		return g.generateChecked(nWords, func(name []byte) bool { return taken(string(name)) })
+	}
+
+	if len(g.lists) == 0 {
+		return "", ErrNoLists
+	}
+	if !g.takeQuota() {
+		return "", ErrQuotaExceeded
+	}
+	rejected := func(name string) bool {
+		b := []byte(name)
+		return taken(name) || g.isHeld(b) || g.isReserved(b)
+	}
+	attempt := 0
+	buf := make([]byte, 0, 64)
+	for attempt < maxRedraws {
+		var err error
+		if buf, err = g.buildChecked(buf[:0], func() int { return g.wordCount(nWords) }, nil); err != nil {
+			return string(buf), err
+		}
+		name := string(buf)
+		for attempt < maxRedraws && rejected(name) {
+			attempt++
+			next := g.onCollision(name, attempt)
+			if g.maxLen > 0 && len(next) > g.maxLen {
+				next = string(g.truncate([]byte(next)))
+			}
+			if next == name || next == "" {
+				name = ""
+				break
+			}
+			name = next
+		}
+		if name != "" && !rejected(name) {
+			if g.avoidPrefix > 0 || g.minDist > 0 {
+				g.takePrev([]byte(name), true)
+			}
+			return name, nil
+		}
+	}
+	return "", ErrExhausted
+}
+
+/**
+ * generateChecked runs the shared checks then redraws while reject holds
+ * @param nWords int optional override for number of words
+ * @param reject func([]byte) bool reports candidates that must be redrawn
+ * @return string generated name and error if any
+ */
+func (g *Generator) generateChecked(nWords int, reject func([]byte) bool) (string, error) {
+	if len(g.lists) == 0 {
+		return "", ErrNoLists
+	}
+	if !g.takeQuota() {
+		return "", ErrQuotaExceeded
+	}
+	var err error
+	s := pooledString(func(dst []byte) []byte {
+		dst, err = g.buildChecked(dst, func() int { return g.wordCount(nWords) }, reject)
+		return dst
+	})
+	return s, err
+}
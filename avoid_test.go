@@ -0,0 +1,127 @@
+package namemachine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestGenerateAvoidingFoldIgnoresCase checks a differently cased taken name is rejected
+ * by the folding variant while the exact variant lets it through
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateAvoidingFoldIgnoresCase(t *testing.T) {
+	g, err := NewFromFiles(map[string][]string{"a.txt": {"brave", "calm"}}, Options{Words: 1, Upper: true, Seed: 5})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	taken := FoldedSet("brave")
+	for i := 0; i < 20; i++ {
+		name, err := g.GenerateAvoidingFold(0, taken)
+		if err != nil {
+			t.Fatalf("GenerateAvoidingFold: %v", err)
+		}
+		if name != "CALM" {
+			t.Fatalf("got %q want CALM since BRAVE folds onto a taken name", name)
+		}
+	}
+
+	exact := map[string]bool{"brave": true}
+	sawBrave := false
+	for i := 0; i < 40; i++ {
+		name, _ := g.GenerateAvoiding(0, func(s string) bool { return exact[s] })
+		sawBrave = sawBrave || name == "BRAVE"
+	}
+	if !sawBrave {
+		t.Fatal("exact avoiding should not reject a differently cased name")
+	}
+
+	if _, err := g.GenerateAvoidingFold(0, FoldedSet("Brave", "CALM")); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("fully taken space got %v want ErrExhausted", err)
+	}
+}
+
+/**
+ * TestGenerateMatchingNeedsDigit requires a digit only the slug can supply
+ * and checks every name complies while an impossible pattern exhausts
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateMatchingNeedsDigit(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 2, ASCIIOnly: true, SlugLength: 6, Seed: 13})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	re := regexp.MustCompile(`[0-9]`)
+	for i := 0; i < 50; i++ {
+		name, err := g.GenerateMatching(re, 0)
+		if err != nil {
+			t.Fatalf("GenerateMatching: %v", err)
+		}
+		if !re.MatchString(name) {
+			t.Fatalf("%q has no digit", name)
+		}
+	}
+	if _, err := g.GenerateMatching(regexp.MustCompile(`^$`), 0); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("impossible pattern got %v want ErrExhausted", err)
+	}
+}
+
+/**
+ * TestOnCollisionMutatesCandidate appends -N to taken names and checks the loop
+ * ends with a unique name while an unchanged return falls back to redrawing
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestOnCollisionMutatesCandidate(t *testing.T) {
+	calls := 0
+	opts := Options{Words: 1, Seed: 9, OnCollision: func(name string, attempt int) string {
+		calls++
+		base, _, _ := strings.Cut(name, "-")
+		return fmt.Sprintf("%s-%d", base, attempt+1)
+	}}
+	g, err := NewFromFiles(map[string][]string{"a.txt": {"otter"}}, opts)
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	taken := map[string]bool{"otter": true, "otter-2": true}
+	name, err := g.GenerateAvoiding(0, func(s string) bool { return taken[s] })
+	if err != nil || name != "otter-3" {
+		t.Fatalf("got %q %v want otter-3", name, err)
+	}
+	if calls != 2 {
+		t.Fatalf("callback ran %d times want 2", calls)
+	}
+
+	// the renamed name is cut to MaxTotalLen and must skip ReservedWords
+	opts.OnCollision = func(name string, attempt int) string {
+		base, _, _ := strings.Cut(name, "_")
+		return fmt.Sprintf("%s_%d_padding", base, attempt+1)
+	}
+	opts.MaxTotalLen = 7
+	opts.ReservedWords = []string{"otter_2"}
+	g, err = NewFromFiles(map[string][]string{"a.txt": {"otter"}}, opts)
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	name, err = g.GenerateAvoiding(0, func(s string) bool { return s == "otter" })
+	if err != nil || name != "otter_3" {
+		t.Fatalf("got %q %v want otter_3", name, err)
+	}
+	opts.MaxTotalLen, opts.ReservedWords = 0, nil
+
+	opts.OnCollision = func(name string, _ int) string { return name }
+	g, _ = NewFromFiles(map[string][]string{"a.txt": {"otter", "fox"}}, opts)
+	for i := 0; i < 20; i++ {
+		if name, err := g.GenerateAvoiding(0, func(s string) bool { return s == "otter" }); err != nil || name != "fox" {
+			t.Fatalf("unchanged return should redraw got %q %v", name, err)
+		}
+	}
+	if _, err := g.GenerateAvoiding(0, func(string) bool { return true }); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("everything taken got %v want ErrExhausted", err)
+	}
+}
@@ -0,0 +1,70 @@
+package namemachine
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+/**
+ * CounterStore persists the next counter value across process restarts
+ * Load returns zero for a fresh store and Save records a value the counter must
+ * resume from implementations must be safe for use by one generator
+ */
+type CounterStore interface {
+	Load() (uint64, error)
+	Save(uint64) error
+}
+
+// defaultCounterSaveEvery is the block of values reserved per Save
+const defaultCounterSaveEvery = 1024
+
+/**
+ * openCounterStore resumes the counter from store and reserves the first block
+ * saving ahead means a crash skips at most one block instead of repeating names
+ * @param store CounterStore backing store
+ * @param every int values reserved per Save zero means the default
+ * @return error if the store cannot be loaded or saved
+ */
+func (g *Generator) openCounterStore(store CounterStore, every int) error {
+	if every < 0 {
+		return fmt.Errorf("CounterSaveEvery %d must not be negative", every)
+	}
+	if every == 0 {
+		every = defaultCounterSaveEvery
+	}
+	start, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("load counter: %w", err)
+	}
+	g.store = store
+	g.storeBlock = uint64(every)
+	g.counter = start
+	g.reserved = start
+	return g.reserveCounter(start)
+}
+
+/**
+ * reserveCounter makes sure counter value n is covered by a Save
+ * the fast path is a single atomic load so only one call per block touches the store
+ * @param n uint64 counter value about to be handed out
+ * @return error if saving the next block fails
+ */
+func (g *Generator) reserveCounter(n uint64) error {
+	if g.store == nil || n < atomic.LoadUint64(&g.reserved) {
+		return nil
+	}
+	g.storeMu.Lock()
+	defer g.storeMu.Unlock()
+	next := g.reserved
+	for next <= n {
+		next += g.storeBlock
+	}
+	if next == g.reserved {
+		return nil
+	}
+	if err := g.store.Save(next); err != nil {
+		return fmt.Errorf("save counter: %w", err)
+	}
+	atomic.StoreUint64(&g.reserved, next)
+	return nil
+}
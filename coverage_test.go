@@ -19,7 +19,7 @@ func TestNormalizeAndFilter(t *testing.T) {
 	in := []string{
 		"Hello", "héllö", "OK", "go", "tool", "tooo", "dup", "dup", "A😊", "B", "éclair",
 	}
-	out := normalizeAndFilter(in, true, true, 3, 4)
+	out := normalizeAndFilter(in, Options{Lowercase: true, ASCIIOnly: true, MinLen: 3, MaxLen: 4})
 	want := []string{"tool", "tooo", "dup"}
 	if !reflect.DeepEqual(out, want) {
 		t.Fatalf("normalizeAndFilter got %v want %v", out, want)
@@ -89,15 +89,15 @@ func TestMergeListsStrategies(t *testing.T) {
 	names := []string{"a/x.txt", "a/y.txt", "b/z.txt"}
 
 	// by dir with cross dedup
+	// second bucket b only had foo so cross dedup empties it and it is dropped
 	lists, ids := mergeLists(files, names, Options{Strategy: MergeByDir, CrossDedup: true})
-	if len(lists) != len(ids) || len(lists) != 2 {
-		t.Fatalf("by dir expected 2 lists got %d ids %v", len(lists), ids)
+	if len(lists) != len(ids) || len(lists) != 1 {
+		t.Fatalf("by dir expected 1 list got %d ids %v", len(lists), ids)
 	}
-	if ids[0] != "a" && ids[1] != "b" && ids[0] != "." {
+	if ids[0] != "a" {
 		t.Fatalf("unexpected ids %v", ids)
 	}
 	// first bucket should hold foo bar bar baz after normalize then dedup stable becomes foo bar baz
-	// second bucket b had foo but cross dedup removes it may become empty
 	if len(lists[0]) == 0 {
 		t.Fatal("first list unexpectedly empty")
 	}
@@ -140,7 +140,7 @@ func TestRandomSlugInto(t *testing.T) {
 func newTestGen() *Generator {
 	return &Generator{
 		lists:      [][]string{{"alpha", "beta"}, {"one", "two"}},
-		delim:      '_',
+		delim:      []byte("_"),
 		wordsExact: 2,
 		slugLen:    0,
 		rng:        rand.New(rand.NewSource(1)),
@@ -179,7 +179,7 @@ func TestGenerateIntoCappedAndAlloc(t *testing.T) {
 func TestGenerateSlugAndWriteTo(t *testing.T) {
 	g := &Generator{
 		lists:      [][]string{{"red"}},
-		delim:      '-',
+		delim:      []byte("-"),
 		wordsExact: 1,
 		slugLen:    6,
 		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
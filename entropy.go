@@ -0,0 +1,98 @@
+package namemachine
+
+import (
+	"fmt"
+	"math"
+)
+
+/**
+ * Entropy estimates the bits of randomness in the weakest name these options produce
+ * words count log2 of their list sizes for the smallest possible word count and the
+ * slug adds log2 of its alphabet per char when every name carries it counters add
+ * nothing since they are predictable and LengthBias is treated as uniform
+ * @return float64 bits and error when the selection is invalid
+ */
+func (o Options) Entropy() (float64, error) {
+	rep, err := o.Plan()
+	if err != nil {
+		return 0, err
+	}
+	return o.entropyFor(rep)
+}
+
+/**
+ * WithEntropy returns a copy of the options that meets at least bits of entropy
+ * word settings are kept and the slug is lengthened to cover any shortfall
+ * an existing config that already meets the target comes back unchanged
+ * @param bits float64 required minimum entropy in bits
+ * @return Options adjusted copy and error when the target cannot be met
+ */
+func (o Options) WithEntropy(bits float64) (Options, error) {
+	rep, err := o.Plan()
+	if err != nil {
+		return o, err
+	}
+	have, err := o.entropyFor(rep)
+	if err != nil || have >= bits {
+		return o, err
+	}
+	if !o.slugOnEvery() {
+		return o, fmt.Errorf("SuffixStrategy or SlugProbability leaves some names without a slug so %.0f bits cannot be reached", bits)
+	}
+	alphabet, err := slugAlphabet(o.SlugKind, o.SlugAvoid)
+	if err != nil {
+		return o, err
+	}
+	if len(alphabet) < 2 {
+		return o, fmt.Errorf("a one character slug alphabet adds no entropy")
+	}
+	perChar := math.Log2(float64(len(alphabet)))
+	out := o
+	out.SlugLength += int(math.Ceil((bits - have) / perChar))
+	return out, nil
+}
+
+/**
+ * entropyFor computes Entropy from an already built plan
+ * @param rep PlanReport selection for these options
+ * @return float64 bits and error for a slug alphabet that cannot be built
+ */
+func (o Options) entropyFor(rep PlanReport) (float64, error) {
+	o.norm()
+	sizes := make([]int, len(rep.Lists))
+	for i, l := range rep.Lists {
+		sizes[i] = l.Words
+	}
+
+	bits := 0.0
+	counts := planWordCounts(o)
+	for i, n := range counts {
+		b := 0.0
+		for j := 0; j < n; j++ {
+			b += math.Log2(float64(sizes[j%len(sizes)]))
+		}
+		if i == 0 || b < bits {
+			bits = b
+		}
+	}
+
+	if o.SlugLength > 0 && o.slugOnEvery() {
+		alphabet, err := slugAlphabet(o.SlugKind, o.SlugAvoid)
+		if err != nil {
+			return 0, err
+		}
+		bits += float64(o.SlugLength) * math.Log2(float64(len(alphabet)))
+	}
+	return bits, nil
+}
+
+/**
+ * slugOnEvery reports whether every name carries the slug once SlugLength is set
+ * @return bool false when SlugProbability or SuffixStrategy can leave it off
+ */
+func (o Options) slugOnEvery() bool {
+	if o.SlugProbability > 0 && o.SlugProbability < 1 {
+		return false
+	}
+	return o.CounterWidth <= 0 || o.SuffixStrategy == SuffixBoth || o.SuffixStrategy == SuffixSlug
+}
@@ -0,0 +1,39 @@
+package namemachine
+
+import (
+	"math"
+	"testing"
+)
+
+/**
+ * TestWithEntropyMeetsTarget checks the computed config has at least 2^bits combinations
+ * and that a config already above the target is left alone
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestWithEntropyMeetsTarget(t *testing.T) {
+	base := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 2, Seed: 1}
+	for _, bits := range []float64{16, 64, 128} {
+		opts, err := base.WithEntropy(bits)
+		if err != nil {
+			t.Fatalf("WithEntropy(%v): %v", bits, err)
+		}
+		rep, err := opts.Plan()
+		if err != nil {
+			t.Fatalf("Plan: %v", err)
+		}
+		// combinations times slug space in log2 form to avoid overflow
+		got := math.Log2(float64(rep.Combinations)) + float64(opts.SlugLength)*5
+		if got < bits {
+			t.Fatalf("target %v bits got %.1f with slug %d", bits, got, opts.SlugLength)
+		}
+		if e, _ := opts.Entropy(); e < bits {
+			t.Fatalf("Entropy %.1f below target %v", e, bits)
+		}
+	}
+
+	opts, err := base.WithEntropy(1)
+	if err != nil || opts.SlugLength != 0 {
+		t.Fatalf("small target should not add a slug got %d %v", opts.SlugLength, err)
+	}
+}
@@ -0,0 +1,319 @@
+package namemachine
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/**
+ * NameAt returns the name at index in the ordered space of nWords word names
+ * word i comes from list i modulo the list count and the last word varies fastest
+ * so index zero is the first word of every list the rng is never touched
+ * slugs and counters are left off since they carry no order
+ * @param index *big.Int zero based index below the product of the list sizes
+ * @param nWords int word count zero uses Words
+ * @return string name and error when the index is out of range
+ */
+func (g *Generator) NameAt(index *big.Int, nWords int) (string, error) {
+	if len(g.lists) == 0 {
+		return "", ErrNoLists
+	}
+	count := g.enumWords(nWords)
+	if count <= 0 {
+		return "", fmt.Errorf("NameAt needs nWords or Words greater than zero")
+	}
+	if index == nil || index.Sign() < 0 {
+		return "", fmt.Errorf("NameAt index %v out of range", index)
+	}
+
+	// peel digits off the back so the last word is the least significant
+	picks := make([]int, count)
+	rest := new(big.Int).Set(index)
+	var digit, base big.Int
+	for i := count - 1; i >= 0; i-- {
+		l := g.lists[i%len(g.lists)]
+		base.SetInt64(int64(len(l)))
+		rest.QuoRem(rest, &base, &digit)
+		picks[i] = int(digit.Int64())
+	}
+	if rest.Sign() != 0 {
+		return "", fmt.Errorf("NameAt index %v out of range for %d words", index, count)
+	}
+	return g.render(picks), nil
+}
+
+/**
+ * render joins the words at picks the way build would without any suffix
+ * FixedWords literals are spliced in so picks only cover sampled positions
+ * NoSlugDelimiter joins them only when build could write the name without a slug
+ * @param picks []int word index per position already checked against the lists
+ * @return string name
+ */
+func (g *Generator) render(picks []int) string {
+	words := make([]string, len(picks))
+	for i, p := range picks {
+		words[i] = g.lists[i%len(g.lists)][p]
+	}
+	var src []int
+	if g.fixed != nil && len(words) > 0 {
+		words, src = g.interleave(words, nil, nil)
+	}
+
+	var dst []byte
+	bare := g.bareJoin(g.slugOnEvery())
+	for i, w := range words {
+		if i > 0 && !g.noDelim {
+			if bare {
+				dst = append(dst, g.bareDelim)
+			} else {
+				dst = append(dst, g.wordDelim(i-1)...)
+			}
+		}
+		if id, ok := g.idAt(src, i); ok {
+			dst = append(dst, id...)
+			dst = append(dst, listIDSep)
+		}
+		dst = g.appendWord(dst, w)
+	}
+	if g.maxLen > 0 && len(dst) > g.maxLen {
+		dst = g.truncate(dst)
+	}
+	return string(dst)
+}
+
+/**
+ * GenerateIndices draws a name as the index of each word in its list
+ * store the indices and rebuild the name later with RenderIndices on a generator
+ * over the same corpus and options slugs and counters are not drawn
+ * the rendered name goes through the same checks as Generate and is redrawn when rejected
+ * @param nWords int optional override for number of words
+ * @return []int word index per position and int the word count nil and zero
+ * when the quota is spent or every redraw was rejected
+ */
+func (g *Generator) GenerateIndices(nWords int) ([]int, int) {
+	if len(g.lists) == 0 || !g.takeQuota() {
+		return nil, 0
+	}
+	if !g.checked() {
+		count := g.wordCount(nWords)
+		return g.drawIndices(count), count
+	}
+	for try := 0; try < maxRedraws; try++ {
+		count := g.wordCount(nWords)
+		picks := g.drawIndices(count)
+		name := []byte(g.render(picks))
+		if g.isHeld(name) || g.isReserved(name) {
+			continue
+		}
+		if (g.avoidPrefix > 0 || g.minDist > 0) && !g.takePrev(name, try == maxRedraws-1) {
+			continue
+		}
+		return picks, count
+	}
+	return nil, 0
+}
+
+/**
+ * drawIndices draws the word index of each of count positions under one lock
+ * @param count int word count
+ * @return []int word index per position
+ */
+func (g *Generator) drawIndices(count int) []int {
+	picks := make([]int, count)
+	ent := entropyBuf{src: g.entropy}
+	g.lock()
+	for i := range picks {
+		li := i % len(g.lists)
+		picks[i] = g.pickAt(li, g.weighted(i), &ent)
+		for try := 0; g.noRepeat && i > 0 && g.lists[li][picks[i]] == g.lists[(i-1)%len(g.lists)][picks[i-1]] && try < repeatTries; try++ {
+			picks[i] = g.pickAt(li, g.weighted(i), &ent)
+		}
+	}
+	g.unlock()
+	return picks
+}
+
+/**
+ * RenderIndices rebuilds the name GenerateIndices described
+ * @param indices []int word index per position
+ * @return string name or empty when an index is outside its list
+ */
+func (g *Generator) RenderIndices(indices []int) string {
+	if len(g.lists) == 0 {
+		return ""
+	}
+	for i, p := range indices {
+		if p < 0 || p >= len(g.lists[i%len(g.lists)]) {
+			return ""
+		}
+	}
+	return g.render(indices)
+}
+
+/**
+ * NameFor maps key to a fixed name so a user id always gets the same one
+ * the key and generator seed are hashed with sha256 to pick a word count the
+ * generator can produce and an index into that NameAt space the rng is never
+ * touched so the name only changes with the corpus options or Seed
+ * @param key string stable identifier such as a user id
+ * @return string name empty when there are no lists
+ */
+func (g *Generator) NameFor(key string) string {
+	if len(g.lists) == 0 {
+		return ""
+	}
+	counts := g.wordCounts()
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], uint64(g.seed))
+	h := sha256.New()
+	h.Write(seed[:])
+	h.Write([]byte(key))
+	sum := h.Sum(nil)
+
+	count := counts[binary.LittleEndian.Uint64(sum[:8])%uint64(len(counts))]
+	if count <= 0 {
+		return ""
+	}
+	index := new(big.Int).SetBytes(sum[8:])
+	index.Mod(index, g.Combinations(count))
+	name, _ := g.NameAt(index, count)
+	return name
+}
+
+/**
+ * Combinations is the size of the NameAt space for nWords words
+ * the product of the sizes of the lists each word position draws from
+ * @param nWords int word count zero uses Words
+ * @return *big.Int count zero when there are no lists or no word count
+ */
+func (g *Generator) Combinations(nWords int) *big.Int {
+	total := new(big.Int)
+	count := g.enumWords(nWords)
+	if len(g.lists) == 0 || count <= 0 {
+		return total
+	}
+	total.SetInt64(1)
+	var size big.Int
+	for i := 0; i < count; i++ {
+		total.Mul(total, size.SetInt64(int64(len(g.lists[i%len(g.lists)]))))
+	}
+	return total
+}
+
+/**
+ * TotalCombinations is how many distinct names the generator can build for nWords words
+ * the list sizes multiply the way build cycles through them with word i from list
+ * i modulo the list count then each slug multiplies that by its alphabet size to
+ * its length which is the SlugKind alphabet for the main slug and each extra Slugs spec
+ * a main slug that only some names carry adds the slugless names on top so the
+ * factor becomes one plus the slug space counters are left out
+ * @param nWords int word count zero uses Words
+ * @return *big.Int count zero when there are no lists no word count or an empty list
+ */
+func (g *Generator) TotalCombinations(nWords int) *big.Int {
+	total := g.Combinations(nWords)
+	if total.Sign() == 0 {
+		return total
+	}
+	var slugs big.Int
+	for _, sp := range g.slugSpecs {
+		total.Mul(total, slugs.Exp(big.NewInt(int64(len(sp.alphabet))), big.NewInt(int64(sp.n)), nil))
+	}
+	if g.slugLen <= 0 || g.counterWidth > 0 && g.suffix == SuffixCounter {
+		return total
+	}
+	symbols := len(base32)
+	if g.slugAlphabet != nil {
+		symbols = len(g.slugAlphabet)
+	}
+	slugs.Exp(big.NewInt(int64(symbols)), big.NewInt(int64(g.slugLen)), nil)
+	if g.slugProb > 0 && g.slugProb < 1 || g.counterWidth > 0 && (g.suffix == SuffixAlternate || g.suffix == SuffixRandom) {
+		slugs.Add(&slugs, big.NewInt(1))
+	}
+	return total.Mul(total, &slugs)
+}
+
+/**
+ * enumWords resolves the word count for index based enumeration
+ * @param nWords int word count zero uses Words
+ * @return int count zero when neither is set
+ */
+func (g *Generator) enumWords(nWords int) int {
+	if nWords > 0 {
+		return nWords
+	}
+	g.lock()
+	defer g.unlock()
+	return g.wordsExact
+}
+
+/**
+ * Cursor walks the NameAt space in index order without touching the rng
+ * its position marshals to text so a paginated walk can resume later
+ * a cursor is not safe for concurrent use
+ */
+type Cursor struct {
+	g      *Generator
+	nWords int
+	next   *big.Int
+	total  *big.Int
+}
+
+/**
+ * Cursor returns a cursor at index zero of the nWords word space
+ * @param nWords int word count zero uses Words
+ * @return *Cursor fresh cursor
+ */
+func (g *Generator) Cursor(nWords int) *Cursor {
+	count := g.enumWords(nWords)
+	return &Cursor{g: g, nWords: count, next: new(big.Int), total: g.Combinations(count)}
+}
+
+/**
+ * ResumeCursor rebuilds a cursor from MarshalText output
+ * the generator must be built from the same corpus and options to continue the same walk
+ * @param state []byte text from Cursor.MarshalText
+ * @return *Cursor cursor at the saved position and error for malformed state
+ */
+func (g *Generator) ResumeCursor(state []byte) (*Cursor, error) {
+	words, index, ok := strings.Cut(string(state), ":")
+	n, err := strconv.Atoi(words)
+	next, okIndex := new(big.Int).SetString(index, 10)
+	if !ok || err != nil || n <= 0 || !okIndex || next.Sign() < 0 {
+		return nil, fmt.Errorf("malformed cursor state %q", state)
+	}
+	c := g.Cursor(n)
+	if next.Cmp(c.total) > 0 {
+		return nil, fmt.Errorf("cursor state %q is past the end of %v names", state, c.total)
+	}
+	c.next = next
+	return c, nil
+}
+
+/**
+ * Next returns the name at the cursor and advances it
+ * @return string name and bool false once the space is exhausted
+ */
+func (c *Cursor) Next() (string, bool) {
+	if c.next.Cmp(c.total) >= 0 {
+		return "", false
+	}
+	name, err := c.g.NameAt(c.next, c.nWords)
+	if err != nil {
+		return "", false
+	}
+	c.next.Add(c.next, big.NewInt(1))
+	return name, true
+}
+
+/**
+ * MarshalText encodes the word count and next index as words:index
+ * @return []byte state for ResumeCursor and nil error
+ */
+func (c *Cursor) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(c.nWords) + ":" + c.next.String()), nil
+}
@@ -0,0 +1,189 @@
+package namemachine
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+/**
+ * TestCursorWalksEverySpaceOnce checks a cursor yields Combinations distinct names
+ * then reports done and that a marshaled cursor resumes where it stopped
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCursorWalksEverySpaceOnce(t *testing.T) {
+	files := map[string][]string{"a.txt": {"brave", "calm", "eager"}, "b.txt": {"otter", "fox"}}
+	g, err := NewFromFiles(files, Options{Words: 3, Seed: 1})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	total := g.Combinations(0)
+	if total.Int64() != 3*2*3 {
+		t.Fatalf("Combinations = %v want 18", total)
+	}
+
+	c := g.Cursor(0)
+	seen := map[string]bool{}
+	var state []byte
+	for i := 0; ; i++ {
+		if i == 7 {
+			state, _ = c.MarshalText()
+		}
+		name, ok := c.Next()
+		if !ok {
+			break
+		}
+		if seen[name] {
+			t.Fatalf("duplicate %q", name)
+		}
+		seen[name] = true
+	}
+	if int64(len(seen)) != total.Int64() {
+		t.Fatalf("cursor yielded %d names want %v", len(seen), total)
+	}
+	if _, ok := c.Next(); ok {
+		t.Fatal("exhausted cursor kept going")
+	}
+
+	resumed, err := g.ResumeCursor(state)
+	if err != nil {
+		t.Fatalf("ResumeCursor(%s): %v", state, err)
+	}
+	want, _ := g.NameAt(big.NewInt(7), 0)
+	if got, _ := resumed.Next(); got != want {
+		t.Fatalf("resumed at %q want %q", got, want)
+	}
+	if _, err := g.ResumeCursor([]byte("3:99")); err == nil {
+		t.Fatal("state past the end should fail")
+	}
+}
+
+/**
+ * TestGenerateIndicesReplay checks RenderIndices rebuilds the name GenerateDetailed
+ * draws from the same seed and rejects indices outside their list
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateIndicesReplay(t *testing.T) {
+	opts := Options{MinWords: 1, MaxWords: 4, Delimiter: '-', Seed: 9}
+	a, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		idx, n := a.GenerateIndices(0)
+		d := b.GenerateDetailed(0)
+		if n != d.Words || len(idx) != n {
+			t.Fatalf("GenerateIndices gave %d words %v want %d", n, idx, d.Words)
+		}
+		if got := a.RenderIndices(idx); got != d.Name {
+			t.Fatalf("RenderIndices(%v) = %q want %q", idx, got, d.Name)
+		}
+	}
+	if got := a.RenderIndices([]int{-1}); got != "" {
+		t.Fatalf("negative index rendered %q", got)
+	}
+	if got := a.RenderIndices([]int{0, 1 << 30}); got != "" {
+		t.Fatalf("out of range index rendered %q", got)
+	}
+}
+
+/**
+ * TestNameForStable checks NameFor returns the same name for a key across
+ * generators with one seed while distinct keys and seeds mostly differ
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNameForStable(t *testing.T) {
+	a, err := New(Options{MinWords: 2, MaxWords: 3, Seed: 5})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b, err := New(Options{MinWords: 2, MaxWords: 3, Seed: 5})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	other, err := New(Options{MinWords: 2, MaxWords: 3, Seed: 6})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b.Generate(0) // generating must not move NameFor
+	names := make(map[string]bool)
+	reseeded := 0
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		name := a.NameFor(key)
+		if name == "" || name != a.NameFor(key) || name != b.NameFor(key) {
+			t.Fatalf("NameFor(%q) unstable: %q %q %q", key, name, a.NameFor(key), b.NameFor(key))
+		}
+		if other.NameFor(key) != name {
+			reseeded++
+		}
+		names[name] = true
+	}
+	if len(names) < 195 {
+		t.Fatalf("only %d distinct names for 200 keys", len(names))
+	}
+	if reseeded < 195 {
+		t.Fatalf("only %d of 200 names changed with the seed", reseeded)
+	}
+}
+
+/**
+ * TestTotalCombinationsCountsListsAndSlug checks list cycling the slug factor and empty lists
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestTotalCombinationsCountsListsAndSlug(t *testing.T) {
+	g, err := NewFromFiles(map[string][]string{"a": {"x", "y", "z"}, "b": {"p", "q"}}, Options{
+		Strategy:   MergeSingle,
+		SlugLength: 2,
+		Seed:       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.lists) != 1 {
+		t.Fatalf("lists = %d want 1", len(g.lists))
+	}
+	n := int64(5)
+	want := big.NewInt(n * n * n * 32 * 32)
+	if got := g.TotalCombinations(3); got.Cmp(want) != 0 {
+		t.Fatalf("TotalCombinations(3) = %v want %v", got, want)
+	}
+
+	g.lists = append(g.lists, nil)
+	if got := g.TotalCombinations(1); got.Cmp(want.SetInt64(n*32*32)) != 0 {
+		t.Fatalf("one word only touches list 0 got %v want %v", got, want)
+	}
+	if got := g.TotalCombinations(2); got.Sign() != 0 {
+		t.Fatalf("empty list gave %v want 0", got)
+	}
+
+	// extra slugs multiply by their own alphabets and an optional slug adds the bare names
+	g, err = NewFromFiles(map[string][]string{"a": {"x", "y", "z"}}, Options{
+		Strategy:        MergeSingle,
+		SlugLength:      1,
+		SlugProbability: 0.5,
+		Slugs:           []SlugSpec{{Length: 2, Alphabet: "0123456789"}},
+		Seed:            1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.TotalCombinations(1); got.Cmp(want.SetInt64(3*100*(1+32))) != 0 {
+		t.Fatalf("TotalCombinations with extra and optional slugs = %v want %v", got, want)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 20000; i++ {
+		seen[g.Generate(1)] = true
+	}
+	if int64(len(seen)) > want.Int64() {
+		t.Fatalf("generated %d distinct names above the %v total", len(seen), want)
+	}
+}
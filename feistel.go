@@ -0,0 +1,101 @@
+package namemachine
+
+import "math/bits"
+
+/**
+ * feistel is a keyed permutation over the integers zero to limit minus one
+ * a balanced feistel network runs over the smallest even bit width covering limit
+ * and cycle walking folds values that land outside the domain back inside
+ * the result is dense random looking and never repeats for distinct inputs
+ */
+type feistel struct {
+	half  uint      // bits per half of the network
+	mask  uint64    // mask for one half
+	limit uint64    // domain size values are in zero to limit minus one
+	keys  [4]uint64 // one key per round
+}
+
+/**
+ * newFeistel builds a permutation over limit values keyed by seed
+ * @param limit uint64 domain size must be at least one
+ * @param seed uint64 key material for the round keys
+ * @return *feistel permutation ready to use
+ */
+func newFeistel(limit, seed uint64) *feistel {
+	// smallest even width that covers limit minus one with at least two bits
+	width := uint(bits.Len64(limit - 1))
+	if width < 2 {
+		width = 2
+	}
+	if width%2 == 1 {
+		width++
+	}
+
+	f := &feistel{
+		half:  width / 2,
+		mask:  (uint64(1) << (width / 2)) - 1,
+		limit: limit,
+	}
+
+	// derive round keys with splitmix so nearby seeds give unrelated keys
+	s := seed
+	for i := range f.keys {
+		s += 0x9E3779B97F4A7C15
+		f.keys[i] = mix64(s)
+	}
+	return f
+}
+
+/**
+ * permute maps x in the domain to its unique image in the domain
+ * @param x uint64 input value below limit
+ * @return uint64 permuted value below limit
+ */
+func (f *feistel) permute(x uint64) uint64 {
+	for {
+		x = f.round(x)
+		if x < f.limit {
+			return x
+		}
+	}
+}
+
+/**
+ * round runs the full feistel network once over the even bit width
+ * @param x uint64 input value
+ * @return uint64 output value within the network width
+ */
+func (f *feistel) round(x uint64) uint64 {
+	l := (x >> f.half) & f.mask
+	r := x & f.mask
+	for _, k := range f.keys {
+		l, r = r, l^(mix64(r^k)&f.mask)
+	}
+	return l<<f.half | r
+}
+
+/**
+ * mix64 is the splitmix64 finalizer used as the feistel round function
+ * @param z uint64 input
+ * @return uint64 well mixed output
+ */
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+/**
+ * appendBase32Fixed appends v as exactly width base32 digits most significant first
+ * values wider than the field keep only their low bits
+ * @param dst []byte destination buffer
+ * @param v uint64 value to encode
+ * @param width int number of digits to emit
+ * @return []byte the destination buffer with the digits appended
+ */
+func appendBase32Fixed(dst []byte, v uint64, width int) []byte {
+	for i := width - 1; i >= 0; i-- {
+		dst = append(dst, base32[(v>>(5*uint(i)))&31])
+	}
+	return dst
+}
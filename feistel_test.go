@@ -0,0 +1,177 @@
+package namemachine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestFeistelIsPermutation walks odd and even sized domains and asserts every output is unique and in range
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestFeistelIsPermutation(t *testing.T) {
+	for _, limit := range []uint64{1, 2, 7, 1000, 1 << 10, 1 << 15} {
+		f := newFeistel(limit, 42)
+		seen := make([]bool, limit)
+		for i := uint64(0); i < limit; i++ {
+			v := f.permute(i)
+			if v >= limit {
+				t.Fatalf("limit %d: value %d out of range", limit, v)
+			}
+			if seen[v] {
+				t.Fatalf("limit %d: value %d repeated", limit, v)
+			}
+			seen[v] = true
+		}
+	}
+}
+
+/**
+ * TestCounterSuffixUniqueFixedWidth exhausts a three char counter and checks every name is unique and fixed width
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCounterSuffixUniqueFixedWidth(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"},
+		Strategy:     MergeByDir,
+		Words:        2,
+		Delimiter:    '-',
+		CounterWidth: 3,
+		Seed:         7,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	const space = 1 << 15 // 32^3
+	seen := make(map[string]struct{}, space)
+	for i := 0; i < space; i++ {
+		parts := strings.Split(g.Generate(0), "-")
+		if len(parts) != 3 {
+			t.Fatalf("expected word-word-counter got %v", parts)
+		}
+		suffix := parts[2]
+		if len(suffix) != 3 {
+			t.Fatalf("counter %q is not fixed width", suffix)
+		}
+		if _, dup := seen[suffix]; dup {
+			t.Fatalf("counter %q repeated at %d", suffix, i)
+		}
+		seen[suffix] = struct{}{}
+	}
+
+	// a different seed yields a different order over the same space
+	g2, err := New(Options{
+		IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"},
+		Strategy:     MergeByDir,
+		Words:        2,
+		Delimiter:    '-',
+		CounterWidth: 3,
+		Seed:         7,
+		CounterSeed:  8,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	g.counter = 0
+	same := 0
+	for i := 0; i < 32; i++ {
+		a := strings.Split(g.Generate(0), "-")[2]
+		b := strings.Split(g2.Generate(0), "-")[2]
+		if a == b {
+			same++
+		}
+	}
+	if same == 32 {
+		t.Fatal("different counter seeds produced identical sequences")
+	}
+
+	if _, err := New(Options{CounterWidth: maxCounterWidth + 1}); err == nil {
+		t.Fatal("expected error for oversized CounterWidth")
+	}
+}
+
+/**
+ * TestGenerateEExhaustedCounter uses up a one char counter and checks GenerateE reports ErrExhausted
+ * while Generate keeps handing out best effort names
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateEExhaustedCounter(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, CounterWidth: 1, Seed: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 32; i++ {
+		if _, err := g.GenerateE(0); err != nil {
+			t.Fatalf("call %d: unexpected error %v", i, err)
+		}
+	}
+	name, err := g.GenerateE(0)
+	if !errors.Is(err, ErrExhausted) || name == "" {
+		t.Fatalf("expected ErrExhausted with a best effort name got %q %v", name, err)
+	}
+	if g.Generate(0) == "" {
+		t.Fatal("Generate returned empty after exhaustion")
+	}
+
+	var empty Generator
+	if _, err := empty.GenerateE(0); !errors.Is(err, ErrNoLists) {
+		t.Fatalf("expected ErrNoLists got %v", err)
+	}
+}
+
+// memCounterStore is an in memory CounterStore standing in for a file or database
+type memCounterStore struct {
+	v     uint64
+	saves int
+}
+
+func (m *memCounterStore) Load() (uint64, error) { return m.v, nil }
+
+func (m *memCounterStore) Save(v uint64) error { m.v = v; m.saves++; return nil }
+
+/**
+ * TestCounterStoreResumesAfterRestart simulates a restart with a shared store
+ * and checks the second generator never repeats a suffix from the first
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCounterStoreResumesAfterRestart(t *testing.T) {
+	store := &memCounterStore{}
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, CounterWidth: 2, CounterSeed: 11, CounterStore: store, CounterSaveEvery: 8}
+
+	seen := make(map[string]bool)
+	suffix := func(name string) string { return name[strings.LastIndexByte(name, '_')+1:] }
+
+	first, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		name, err := first.GenerateE(0)
+		if err != nil {
+			t.Fatalf("GenerateE: %v", err)
+		}
+		seen[suffix(name)] = true
+	}
+	if store.v < 20 || store.saves < 3 {
+		t.Fatalf("store not advanced: v=%d saves=%d", store.v, store.saves)
+	}
+
+	// restarted process picks up from the saved high water mark
+	second, err := New(opts)
+	if err != nil {
+		t.Fatalf("New after restart: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		s := suffix(second.Generate(0))
+		if seen[s] {
+			t.Fatalf("suffix %q repeated after restart", s)
+		}
+		seen[s] = true
+	}
+}
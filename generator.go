@@ -1,31 +1,176 @@
 package namemachine
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
+	"math"
 	"math/rand"
+	randv2 "math/rand/v2"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 )
 
+var (
+	// ErrNoLists is returned when a generator has no lists to draw from
+	ErrNoLists = errors.New("generator has no lists")
+
+	// ErrExhausted is returned when a name space or retry budget runs out
+	ErrExhausted = errors.New("name space exhausted")
+
+	// ErrQuotaExceeded is returned once MaxGenerations names have been handed out
+	ErrQuotaExceeded = errors.New("generation quota exceeded")
+
+	// ErrEmptyCorpus is returned when no word files exist at all before any selection
+	ErrEmptyCorpus = errors.New("no word files found")
+
+	// ErrEntropy is returned under EntropyFailError when the entropy source fails
+	ErrEntropy = errors.New("entropy source failed")
+)
+
+/**
+ * defaultMaxSlugLength caps SlugLength when Options.MaxSlugLength is zero
+ * far beyond any useful slug yet small enough that a typo cannot allocate megabytes
+ */
+const defaultMaxSlugLength = 256
+
+/**
+ * maxCounterWidth bounds the counter suffix so its space fits in 64 bits
+ */
+const maxCounterWidth = 12
+
 /**
  * Generator produces names from embedded lists with optional slug and custom delimiter
- * thread safe random source guarded by mutex
+ * thread safe random source guarded by mutex unless built with SingleThreaded
  * supports zero allocation generation when caller provides a buffer
  */
 type Generator struct {
-	lists [][]string // in order user requested
-	delim byte
+	lists      [][]string // in order user requested
+	ids        []string   // list ids aligned with lists
+	delim      []byte     // between words and before suffixes one byte unless DelimiterString
+	slugDelim  []byte     // before the slug and counter suffix nil means delim
+	wordDelims []byte     // per boundary delimiters cycling nil means delim everywhere
+	prefixIDs  bool       // write each word as listid:word
+	bareDelim  byte       // word delimiter for names without a slug zero means the usual ones
+	noDelim    bool       // concatenate words with no boundary byte
 
 	wordsExact int
 	minWords   int
 	maxWords   int
 
-	slugLen int
+	allowedCounts []int // word counts drawn uniformly repeats weight a count
+
+	slugLen          int
+	slugAlphabet     []byte          // symbols for the slug nil means base32
+	slugStream       *randv2.ChaCha8 // seeded slug source nil means crypto rand
+	slugGroup        int             // insert slugGroupSep every slugGroup chars zero disables
+	slugGroupSep     byte
+	rejectDegenerate bool       // redraw all digit or single symbol slugs
+	slugProb         float64    // chance a name gets the slug zero or one means always
+	slugSpecs        []slugSpec // extra slugs from Options.Slugs nil means none
+	specLen          int        // bytes the extra slugs add including delimiters
+
+	phraseJoin bool // swap phrase spaces for the delimiter on output
+	upper      bool // uppercase words as they are appended
+	upperRunes bool // uppercase with unicode rules instead of ascii only
+	upperSlug  bool // uppercase the slug and counter suffix too
+	maxLen     int  // truncate names beyond this many bytes zero means no cap
+	softHyphen int  // hyphenate words every softHyphen runes when longer zero disables
+	slugOnly   bool // emit only the slug and counter suffix with no words
+
+	counterWidth int            // base32 chars in the counter suffix zero disables
+	suffix       SuffixStrategy // which of slug and counter a name carries when both are set
+	counter      uint64         // next sequential counter value
+	perm         *feistel       // keyed permutation applied to the counter
+
+	store      CounterStore // persists the counter high water mark nil disables
+	storeBlock uint64       // counter values reserved per Save
+	reserved   uint64       // first counter value not yet covered by a Save
+	storeMu    sync.Mutex   // serializes Save calls
 
-	rngMu sync.Mutex
-	rng   *rand.Rand
+	weights       [][]float64         // cumulative length weights per list nil means uniform
+	posWeighted   []bool              // per word position weighted sampling from PositionSampling
+	seed          int64               // normalized Seed keying NameFor
+	fixed         map[int]string      // FixedWords literals by position nil when unset
+	reservedWords map[string]struct{} // lowercased ReservedWords nil when unset
+	reservedMax   int                 // longest reserved entry in bytes
+	reservedMatch ReservedMatch
+	wordCase      WordCase   // per word casing applied as each word is written
+	cooldown      int        // draws a picked word stays downweighted zero disables
+	noRepeat      bool       // redraw a word equal to the one before it
+	lastUse       [][]uint64 // per list draw tick each word was last picked built lazily
+	ticks         []uint64   // draws made from each list under cooldown
+	cryptoWords   bool       // draw word indices from crypto rand instead of rng
+	entropy       io.Reader  // source for slug and crypto word bytes nil means crypto rand
+	entropyFail   EntropyFailurePolicy
+
+	memberOnce sync.Once           // builds members on the first Contains call
+	members    map[string]struct{} // every word across lists keyed by memberKey
+	foldCase   bool                // membership ignores case lists were lowercased or case folded
+
+	heldMu sync.Mutex          // guards held
+	held   map[string]struct{} // names handed out by Reserve until Released
+	heldN  int64               // len(held) read atomically on the hot path
+
+	uniqueMu sync.Mutex          // guards emitted and emittedN
+	emitted  map[string]struct{} // names GenerateUnique handed out across every word count
+	emittedN map[int]int         // how many of them were built with each word count
+
+	onCollision func(string, int) string // mutates a taken candidate in GenerateAvoiding nil means redraw
+
+	avoidPrefix int        // runes a name may not share with the previous one zero disables
+	minDist     int        // edits a name must differ from the previous one by zero disables
+	prevMu      sync.Mutex // guards prev
+	prev        []byte     // previous accepted name
+	hasPrev     bool
+
+	rngMu          sync.Mutex
+	shapeMu        sync.RWMutex // guards word shape for shard draws writers also hold rngMu
+	shards         *sync.Pool   // contention rngs nil means always wait for rngMu
+	rng            *rand.Rand
+	singleThreaded bool   // skip rngMu entirely caller promises one goroutine
+	position       uint64 // names built so far updated atomically
+	maxGens        uint64 // generation quota zero means unlimited
+	issued         uint64 // generations claimed against maxGens
+}
+
+// maxRedraws bounds how often a name is redrawn to satisfy per call checks
+const maxRedraws = 32
+
+// listIDSep separates a list id from its word under PrefixWithListID
+const listIDSep = ':'
+
+/**
+ * maxStackWords is how many drawn words build keeps on the stack
+ * longer names still work but the word slice moves to the heap
+ */
+const maxStackWords = 16
+
+/**
+ * namePool holds scratch buffers shared by the string api
+ * buffers are returned after the name is copied out so callers never see them
+ */
+var namePool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 64)
+		return &b
+	},
 }
 
+/**
+ * maxPooledBuf caps the buffer size kept in the pool
+ * oversized buffers from unusual configs are left for the gc
+ */
+const maxPooledBuf = 1024
+
 /**
  * New creates a Generator and performs one time loading filtering and merging
  * expensive setup happens once here
@@ -33,35 +178,286 @@ type Generator struct {
  * @return *Generator instance or error
  */
 func New(opts Options) (*Generator, error) {
+	// default parse settings can reuse the corpus parsed once per process
+	if opts.TrimCutset == "" && !opts.NoTrim {
+		opts.norm()
+		files, err := loadAllFiles()
+		if err != nil {
+			return nil, err
+		}
+		return newFromFiles(files, opts)
+	}
+	return newFromFS(listsFS, "lists", opts)
+}
+
+/**
+ * newFromFS loads every txt file under root and builds a generator from them
+ * a missing root or one without txt files is a corpus problem reported apart
+ * from globs that select nothing
+ * @param fsys fs.FS filesystem holding the lists
+ * @param root string directory to walk
+ * @param opts Options configuration
+ * @return *Generator instance or error
+ */
+func newFromFS(fsys fs.FS, root string, opts Options) (*Generator, error) {
+	opts.norm()
+
+	files, err := loadFiles(fsys, root, opts)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("lists root %q: %w", root, ErrEmptyCorpus)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newFromFiles(files, opts)
+}
+
+/**
+ * NewFromTar builds a Generator from the txt entries of a tar stream
+ * entry paths such as adjectives/age.txt are the file keys for globs and ids
+ * and each entry is parsed like an embedded file so nothing touches disk
+ * @param r io.Reader uncompressed tar stream wrap it in gzip.NewReader for tgz
+ * @param opts Options configuration for list selection normalization and behavior
+ * @return *Generator instance or error
+ */
+func NewFromTar(r io.Reader, opts Options) (*Generator, error) {
 	opts.norm()
 
-	files, err := loadAllFiles()
+	files, err := loadTar(r, opts)
 	if err != nil {
 		return nil, err
 	}
+	return newFromFiles(files, opts)
+}
+
+/**
+ * NewFromFiles builds a Generator from caller supplied word files instead of the
+ * embedded lists keys are slash paths such as adjectives/age.txt and values are
+ * the raw words of each file globs strategies and filters apply as in New
+ * the map and its slices are never modified
+ * @param files map[string][]string words per file path
+ * @param opts Options configuration for list selection normalization and behavior
+ * @return *Generator instance or error
+ */
+func NewFromFiles(files map[string][]string, opts Options) (*Generator, error) {
+	opts.norm()
+
+	owned := make(fileWords, len(files))
+	for name, words := range files {
+		owned[name] = append([]string(nil), words...)
+	}
+	return newFromFiles(owned, opts)
+}
 
-	// select files using include and exclude globs
-	selected := globFilter(files, opts.IncludeGlobs, opts.ExcludeGlobs)
+/**
+ * newFromFiles runs selection merging and validation over loaded files
+ * opts must already be normalized
+ * @param files fileWords loaded files owned by the generator
+ * @param opts Options normalized configuration
+ * @return *Generator instance or error
+ */
+func newFromFiles(files fileWords, opts Options) (*Generator, error) {
+	if err := addExtraFS(files, opts); err != nil {
+		return nil, err
+	}
+	addLists(files, opts)
+	if len(files) == 0 {
+		return nil, ErrEmptyCorpus
+	}
 
-	// merge selected files into lists based on strategy
-	lists, _ := mergeLists(files, selected, opts)
+	sel, err := selectLists(files, opts)
+	if err != nil {
+		return nil, err
+	}
+	lists := sel.lists
+	if opts.SlugOnly && opts.SlugLength <= 0 && opts.CounterWidth <= 0 && len(opts.Slugs) == 0 {
+		return nil, fmt.Errorf("SlugOnly needs SlugLength, CounterWidth or Slugs")
+	}
+	if err := checkWordCounts(opts.Words, opts.MinWords, opts.MaxWords); err != nil {
+		return nil, err
+	}
+	for _, n := range opts.AllowedWordCounts {
+		if n <= 0 {
+			return nil, fmt.Errorf("AllowedWordCounts entry %d must be greater than zero", n)
+		}
+	}
+	if opts.MaxGenerations < 0 {
+		return nil, fmt.Errorf("MaxGenerations %d must not be negative", opts.MaxGenerations)
+	}
+	if opts.AvoidCommonPrefix < 0 {
+		return nil, fmt.Errorf("AvoidCommonPrefix %d must not be negative", opts.AvoidCommonPrefix)
+	}
+	if opts.SoftHyphenate < 0 {
+		return nil, fmt.Errorf("SoftHyphenate %d must not be negative", opts.SoftHyphenate)
+	}
+	if opts.WordCase < CaseNone || opts.WordCase > CaseLower {
+		return nil, fmt.Errorf("WordCase %d is unknown", opts.WordCase)
+	}
+	for p, w := range opts.FixedWords {
+		if p < 0 || w == "" {
+			return nil, fmt.Errorf("FixedWords[%d] needs a position of zero or more and a non empty word", p)
+		}
+	}
+	if err := checkEnvVar(opts); err != nil {
+		return nil, err
+	}
+	if opts.RecencyCooldown < 0 {
+		return nil, fmt.Errorf("RecencyCooldown %d must not be negative", opts.RecencyCooldown)
+	}
+	if opts.MinDistance < 0 {
+		return nil, fmt.Errorf("MinDistance %d must not be negative", opts.MinDistance)
+	}
+	if opts.SlugProbability < 0 || opts.SlugProbability > 1 {
+		return nil, fmt.Errorf("SlugProbability %v out of range (0..1)", opts.SlugProbability)
+	}
+	maxSlug := opts.MaxSlugLength
+	if maxSlug <= 0 {
+		maxSlug = defaultMaxSlugLength
+	}
+	if opts.SlugLength > maxSlug {
+		return nil, fmt.Errorf("SlugLength %d exceeds MaxSlugLength %d", opts.SlugLength, maxSlug)
+	}
+	for i, sp := range opts.Slugs {
+		if sp.Length > maxSlug {
+			return nil, fmt.Errorf("Slugs[%d] length %d exceeds MaxSlugLength %d", i, sp.Length, maxSlug)
+		}
+	}
+	if opts.SlugGroup < 0 {
+		return nil, fmt.Errorf("SlugGroup %d must not be negative", opts.SlugGroup)
+	}
+	if opts.CounterWidth < 0 || opts.CounterWidth > maxCounterWidth {
+		return nil, fmt.Errorf("CounterWidth %d out of range (0..%d)", opts.CounterWidth, maxCounterWidth)
+	}
 
-	// require at least one list to proceed
-	if len(lists) == 0 {
-		return nil, fmt.Errorf("no lists selected (IncludeGlobs/ExcludeGlobs matched zero files)")
+	alphabet, err := slugAlphabet(opts.SlugKind, opts.SlugAvoid)
+	if err != nil {
+		return nil, err
+	}
+	delim := []byte{opts.Delimiter}
+	if opts.DelimiterString != "" {
+		delim = []byte(opts.DelimiterString)
+	}
+	var slugDelim []byte
+	if opts.SlugDelimiter != 0 {
+		slugDelim = []byte{opts.SlugDelimiter}
+	}
+	specs, specLen, err := newSlugSpecs(opts.Slugs, len(delim))
+	if err != nil {
+		return nil, err
 	}
 
 	// seed a private rng for this generator
-	r := rand.New(rand.NewSource(opts.Seed))
-	return &Generator{
-		lists:      lists,
-		delim:      opts.Delimiter,
-		wordsExact: opts.Words,
-		minWords:   opts.MinWords,
-		maxWords:   opts.MaxWords,
-		slugLen:    opts.SlugLength,
-		rng:        r,
-	}, nil
+	r := newRand(opts.Seed, opts.StableRandom)
+	if opts.Source != nil {
+		r = rand.New(opts.Source)
+	}
+	g := &Generator{
+		lists:    lists,
+		ids:      sel.ids,
+		foldCase: opts.Lowercase || opts.FoldCaseForDedup,
+
+		cryptoWords:   opts.CryptoWords,
+		entropy:       opts.EntropySource,
+		entropyFail:   opts.OnEntropyFailure,
+		prefixIDs:     opts.PrefixWithListID,
+		bareDelim:     opts.NoSlugDelimiter,
+		noDelim:       opts.NoDelimiter,
+		slugProb:      opts.SlugProbability,
+		avoidPrefix:   opts.AvoidCommonPrefix,
+		minDist:       opts.MinDistance,
+		cooldown:      opts.RecencyCooldown,
+		noRepeat:      opts.NoAdjacentRepeat,
+		seed:          opts.Seed,
+		fixed:         maps.Clone(opts.FixedWords),
+		reservedMatch: opts.ReservedMatch,
+		wordCase:      opts.WordCase,
+		onCollision:   opts.OnCollision,
+		delim:         delim,
+		slugDelim:     slugDelim,
+		wordDelims:    append([]byte(nil), opts.WordDelimiters...),
+		wordsExact:    opts.Words,
+		minWords:      opts.MinWords,
+		maxWords:      opts.MaxWords,
+
+		allowedCounts: append([]int(nil), opts.AllowedWordCounts...),
+		slugLen:       opts.SlugLength,
+		slugGroup:     opts.SlugGroup,
+		slugSpecs:     specs,
+		specLen:       specLen,
+
+		rejectDegenerate: opts.RejectDegenerateSlugs,
+		phraseJoin:       opts.AllowPhrases && opts.PhraseJoin,
+		upper:            opts.Upper,
+		upperRunes:       opts.Upper && opts.UpperRunes,
+		upperSlug:        opts.Upper && !opts.LowerSlug,
+		maxLen:           opts.MaxTotalLen,
+		softHyphen:       opts.SoftHyphenate,
+		slugOnly:         opts.SlugOnly,
+		suffix:           opts.SuffixStrategy,
+		maxGens:          uint64(opts.MaxGenerations),
+		rng:              r,
+
+		singleThreaded: opts.SingleThreaded,
+	}
+	// contended calls draw from shards unless RecencyCooldown state or a caller
+	// Source must be shared by every draw
+	if !opts.SingleThreaded && opts.RecencyCooldown == 0 && opts.Source == nil {
+		g.shards = newShards(opts.Seed, opts.StableRandom)
+	}
+	g.reservedWords, g.reservedMax = reservedSet(opts.ReservedWords)
+	if g.reservedMatch == ReservedAnyWord {
+		for p, w := range g.fixed {
+			if g.reservedHas([]byte(w)) {
+				return nil, fmt.Errorf("FixedWords[%d] %q is a reserved word", p, w)
+			}
+		}
+	}
+	if opts.LengthBias != 0 {
+		g.weights = lengthWeights(lists, opts.LengthBias)
+	}
+	for i, m := range opts.PositionSampling {
+		switch m {
+		case SamplingDefault:
+			g.posWeighted = append(g.posWeighted, g.weights != nil)
+		case SamplingUniform:
+			g.posWeighted = append(g.posWeighted, false)
+		case SamplingWeighted:
+			if g.weights == nil {
+				return nil, fmt.Errorf("PositionSampling[%d] is SamplingWeighted but LengthBias is zero", i)
+			}
+			g.posWeighted = append(g.posWeighted, true)
+		default:
+			return nil, fmt.Errorf("PositionSampling[%d] has unknown mode %d", i, m)
+		}
+	}
+	if opts.SlugGroup > 0 {
+		g.slugGroupSep = opts.SlugGroupSep
+	}
+	if opts.SlugAvoid != "" || opts.SlugKind != SlugBase32 {
+		g.slugAlphabet = alphabet
+	}
+	if opts.SeededSlugs {
+		g.slugStream = newSlugStream(opts.Seed)
+	}
+
+	// key the counter permutation from its own seed or the generator seed
+	if opts.CounterWidth > 0 {
+		seed := opts.CounterSeed
+		if seed == 0 {
+			seed = opts.Seed
+		}
+		if opts.MaxTotalLen > 0 && opts.MaxTotalLen <= g.suffixMax(opts.CounterWidth) {
+			return nil, fmt.Errorf("MaxTotalLen %d leaves no room for a word before the %d byte slug and counter suffix", opts.MaxTotalLen, g.suffixMax(opts.CounterWidth))
+		}
+		g.counterWidth = opts.CounterWidth
+		g.perm = newFeistel(uint64(1)<<(5*uint(opts.CounterWidth)), uint64(seed))
+		if opts.CounterStore != nil {
+			if err := g.openCounterStore(opts.CounterStore, opts.CounterSaveEvery); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g, nil
 }
 
 /**
@@ -73,116 +469,1527 @@ func New(opts Options) (*Generator, error) {
  * @return []byte slice containing the generated name
  */
 func (g *Generator) GenerateInto(dst []byte, nWords int) []byte {
-	if len(g.lists) == 0 {
-		return dst[:0]
-	}
+	dst, _ = g.GenerateIntoE(dst, nWords)
+	return dst
+}
 
-	// decide word count
-	count := nWords
-	if count <= 0 {
-		if g.wordsExact > 0 {
-			count = g.wordsExact
-		} else {
-			count = g.randWordCount()
-		}
+/**
+ * GenerateIntoE is GenerateInto with an error channel for call time failures
+ * the returned bytes are still a best effort name when err is ErrExhausted
+ * and empty when err is ErrNoLists or ErrQuotaExceeded
+ * @param dst []byte destination buffer provided by the caller
+ * @param nWords int optional override for number of words
+ * @return []byte slice containing the generated name and error if any
+ */
+func (g *Generator) GenerateIntoE(dst []byte, nWords int) ([]byte, error) {
+	if len(g.lists) == 0 {
+		return dst[:0], ErrNoLists
 	}
-	if count <= 0 {
-		count = 1
+	if !g.takeQuota() {
+		return dst[:0], ErrQuotaExceeded
 	}
+	return g.buildFor(dst, func() int { return g.wordCount(nWords) })
+}
 
-	// compute final length to size buffer correctly
-	totalLen := 0
-	for i := 0; i < count; i++ {
-		list := g.lists[i%len(g.lists)]
-
-		// one rng call per word
-		g.rngMu.Lock()
-		idx := g.rng.Intn(len(list))
-		g.rngMu.Unlock()
+/**
+ * checked reports whether names must pass the shared checks in buildChecked
+ * @return bool true while names are held or ReservedWords AvoidCommonPrefix or MinDistance is set
+ */
+func (g *Generator) checked() bool {
+	return atomic.LoadInt64(&g.heldN) > 0 || g.reservedWords != nil || g.avoidPrefix > 0 || g.minDist > 0
+}
 
-		totalLen += len(list[idx])
+/**
+ * buildFor is the build every public generation path shares
+ * it runs buildChecked when any check is active so no path skips them
+ * @param dst []byte destination buffer
+ * @param count func() int word count for each draw called again on a redraw
+ * @return []byte name and error if any
+ */
+func (g *Generator) buildFor(dst []byte, count func() int) ([]byte, error) {
+	if g.checked() {
+		return g.buildChecked(dst, count, nil)
 	}
-	if count > 1 {
-		totalLen += count - 1 // delimiters between words
+	return g.build(dst, count())
+}
+
+/**
+ * buildChecked builds a name redrawing while it is held reserved rejected by taken or
+ * while it is too close to the previous name under AvoidCommonPrefix or MinDistance
+ * a reserved or taken name is never returned while a close one is kept once retries run out
+ * @param dst []byte destination buffer
+ * @param count func() int word count for each draw called again on a redraw
+ * @param taken func([]byte) bool extra rejection check nil means none
+ * @return []byte name and ErrExhausted when every redraw was rejected
+ */
+func (g *Generator) buildChecked(dst []byte, count func() int, taken func([]byte) bool) ([]byte, error) {
+	for try := 0; try < maxRedraws; try++ {
+		var err error
+		dst, err = g.build(dst, count())
+		if g.isHeld(dst) || g.isReserved(dst) || taken != nil && taken(dst) {
+			continue
+		}
+		if (g.avoidPrefix > 0 || g.minDist > 0) && !g.takePrev(dst, try == maxRedraws-1) {
+			continue
+		}
+		return dst, err
 	}
-	if g.slugLen > 0 {
-		totalLen += 1 + g.slugLen // one delimiter plus slug bytes
+	return dst[:0], ErrExhausted
+}
+
+/**
+ * takePrev records name as the previous name unless it opens with the same
+ * AvoidCommonPrefix runes or sits closer than MinDistance edits to the previous one
+ * @param name []byte candidate name
+ * @param force bool record even when the checks fail
+ * @return bool true when the name was accepted
+ */
+func (g *Generator) takePrev(name []byte, force bool) bool {
+	g.prevMu.Lock()
+	defer g.prevMu.Unlock()
+	if !force && g.hasPrev {
+		if g.avoidPrefix > 0 && bytes.Equal(runePrefix(name, g.avoidPrefix), runePrefix(g.prev, g.avoidPrefix)) {
+			return false
+		}
+		if g.minDist > 0 && levenshtein(name, g.prev) < g.minDist {
+			return false
+		}
 	}
+	g.prev = append(g.prev[:0], name...)
+	g.hasPrev = true
+	return true
+}
 
-	// ensure capacity without allocating if caller provided enough space
-	if cap(dst) < totalLen {
-		// fall back to allocation only if caller did not give enough space
-		dst = make([]byte, 0, totalLen)
-	} else {
-		dst = dst[:0]
+/**
+ * runePrefix returns the first n runes of b or all of b when shorter
+ * @param b []byte name
+ * @param n int rune count
+ * @return []byte prefix sharing b's memory
+ */
+func runePrefix(b []byte, n int) []byte {
+	for i, k := 0, 0; i < len(b); k++ {
+		if k == n {
+			return b[:i]
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		i += size
 	}
+	return b
+}
 
-	// build words into dst
-	for i := 0; i < count; i++ {
-		if i > 0 {
-			dst = append(dst, g.delim)
+/**
+ * levenshtein counts the rune insertions deletions and substitutions turning a into b
+ * @param a []byte first name
+ * @param b []byte second name
+ * @return int edit distance
+ */
+func levenshtein(a, b []byte) int {
+	ra, rb := []rune(string(a)), []rune(string(b))
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			diag, row[j] = row[j], min(row[j]+1, row[j-1]+1, diag+cost)
 		}
-		list := g.lists[i%len(g.lists)]
+	}
+	return row[len(rb)]
+}
 
-		// choose a word using the rng
-		g.rngMu.Lock()
-		w := list[g.rng.Intn(len(list))]
-		g.rngMu.Unlock()
+/**
+ * takeQuota claims one generation against MaxGenerations before building
+ * @return bool false once the quota is spent
+ */
+func (g *Generator) takeQuota() bool {
+	return g.maxGens == 0 || atomic.AddUint64(&g.issued, 1) <= g.maxGens
+}
 
-		dst = append(dst, w...)
+/**
+ * takeQuotaN claims n generations at once for all or nothing callers
+ * a claim that does not fit is handed back so smaller calls can still use the rest
+ * @param n int generations to claim greater than zero
+ * @return bool false when fewer than n remain
+ */
+func (g *Generator) takeQuotaN(n int) bool {
+	if g.maxGens == 0 {
+		return true
+	}
+	if atomic.AddUint64(&g.issued, uint64(n)) <= g.maxGens {
+		return true
 	}
+	g.returnQuota(n)
+	return false
+}
 
-	// append slug directly into dst no temp slice
-	if g.slugLen > 0 {
-		dst = append(dst, g.delim)
-		dst = randomSlugInto(dst, g.slugLen)
+/**
+ * returnQuota hands back n generations claimed by takeQuotaN that produced nothing
+ * @param n int generations to return
+ * @return void
+ */
+func (g *Generator) returnQuota(n int) {
+	if g.maxGens != 0 {
+		atomic.AddUint64(&g.issued, ^uint64(n-1))
+	}
+}
+
+/**
+ * GenerateRangeInto writes a name whose word count is drawn from min to max inclusive
+ * the range overrides the generator word count settings for this call only
+ * @param dst []byte destination buffer provided by the caller
+ * @param minWords int inclusive lower bound values below one become one
+ * @param maxWords int inclusive upper bound raised to minWords when smaller
+ * @return []byte slice containing the generated name
+ */
+func (g *Generator) GenerateRangeInto(dst []byte, minWords, maxWords int) []byte {
+	if len(g.lists) == 0 || !g.takeQuota() {
+		return dst[:0]
 	}
+	dst, _ = g.buildFor(dst, func() int {
+		if g.slugOnly {
+			return 0
+		}
+		return g.randRange(minWords, maxWords)
+	})
 	return dst
 }
 
 /**
- * Generate is a convenience wrapper that returns a string
- * this allocates for the byte slice and for the string copy
- * @param nWords int optional override for number of words
+ * GenerateRange is the string form of GenerateRangeInto
+ * @param minWords int inclusive lower bound
+ * @param maxWords int inclusive upper bound
  * @return string generated name
  */
-func (g *Generator) Generate(nWords int) string {
-	b := g.GenerateInto(nil, nWords) // will allocate exactly once for byte slice
-	return string(b)                 // second allocation string copy
+func (g *Generator) GenerateRange(minWords, maxWords int) string {
+	return pooledString(func(dst []byte) []byte {
+		return g.GenerateRangeInto(dst, minWords, maxWords)
+	})
 }
 
 /**
- * randWordCount picks a word count using min and max bounds
- * returns an (old) docker like default of two when bounds are not set
- * @return int chosen word count
+ * GenerateExcludingCounts generates a name whose word count avoids every value in exclude
+ * the count is drawn from the configured counts minus the excluded ones
+ * so a caller can force a structure different from an existing name
+ * @param exclude []int word counts this call must not use
+ * @return string generated name and error when every configured count is excluded
  */
-func (g *Generator) randWordCount() int {
-	if g.minWords <= 0 && g.maxWords <= 0 {
-		return 2
+func (g *Generator) GenerateExcludingCounts(exclude []int) (string, error) {
+	var keep []int
+	for _, n := range g.wordCounts() {
+		if !containsInt(exclude, n) {
+			keep = append(keep, n)
+		}
+	}
+	if len(keep) == 0 {
+		return "", fmt.Errorf("every word count is excluded (%v)", exclude)
+	}
+	g.lock()
+	count := keep[g.rng.Intn(len(keep))]
+	g.unlock()
+	return g.GenerateE(count)
+}
+
+/**
+ * wordCounts lists the word counts the generator settings can produce
+ * AllowedWordCounts keep their repeats so weighting carries over
+ * @return []int candidate counts
+ */
+func (g *Generator) wordCounts() []int {
+	g.lock()
+	exact, min, max := g.wordsExact, g.minWords, g.maxWords
+	allowed := append([]int(nil), g.allowedCounts...)
+	g.unlock()
+	switch {
+	case exact > 0:
+		return []int{exact}
+	case len(allowed) > 0:
+		return allowed
+	case min <= 0 && max <= 0:
+		return []int{2}
 	}
-	min := g.minWords
-	max := g.maxWords
 	if min <= 0 {
 		min = 1
 	}
 	if max < min {
 		max = min
 	}
-	g.rngMu.Lock()
-	n := g.rng.Intn(max-min+1) + min
-	g.rngMu.Unlock()
-	return n
+	out := make([]int, 0, max-min+1)
+	for n := min; n <= max; n++ {
+		out = append(out, n)
+	}
+	return out
 }
 
 /**
- * WriteTo writes a generated name to an io Writer
- * uses a small stack buffer and the zero allocation path inside GenerateInto
- * @param w io.Writer destination writer
+ * SetWords switches the generator to exactly n words per name from now on
+ * zero falls back to the range or AllowedWordCounts set at New or by SetWordRange
+ * safe to call while other goroutines generate
+ * @param n int exact word count zero clears it
+ * @return error when n is negative
+ */
+func (g *Generator) SetWords(n int) error {
+	if err := checkWordCounts(n, 0, 0); err != nil {
+		return err
+	}
+	g.lock()
+	g.shapeMu.Lock()
+	g.wordsExact = n
+	g.shapeMu.Unlock()
+	g.unlock()
+	return nil
+}
+
+/**
+ * SetWordRange switches the generator to a word count drawn from min to max
+ * it clears any exact count and AllowedWordCounts so the range takes effect
+ * safe to call while other goroutines generate
+ * @param min int inclusive lower bound zero means one
+ * @param max int inclusive upper bound zero means min
+ * @return error when the bounds break the rules New applies
+ */
+func (g *Generator) SetWordRange(min, max int) error {
+	if err := checkWordCounts(0, min, max); err != nil {
+		return err
+	}
+	g.lock()
+	g.shapeMu.Lock()
+	g.wordsExact, g.minWords, g.maxWords = 0, min, max
+	g.allowedCounts = nil
+	g.shapeMu.Unlock()
+	g.unlock()
+	return nil
+}
+
+/**
+ * checkEnvVar rejects options the EnvVar preset cannot clear without changing
+ * what the caller asked for each could put a digit or symbol in the name
+ * @param opts Options normalized configuration
+ * @return error naming the first offending option nil when EnvVar is off
+ */
+func checkEnvVar(opts Options) error {
+	if !opts.EnvVar {
+		return nil
+	}
+	if opts.SlugOnly {
+		return fmt.Errorf("EnvVar names start with a letter so SlugOnly is not allowed")
+	}
+	for p, w := range opts.FixedWords {
+		if !isEnvWord(w, false) {
+			return fmt.Errorf("FixedWords[%d] %q is not a letter led ascii word as EnvVar needs", p, w)
+		}
+	}
+	for i, sp := range opts.Slugs {
+		if sp.Position == SlugPrefix {
+			return fmt.Errorf("Slugs[%d] cannot open an EnvVar name", i)
+		}
+		if sp.Alphabet != "" && !isEnvWord("a"+sp.Alphabet, false) {
+			return fmt.Errorf("Slugs[%d] alphabet %q has symbols EnvVar does not allow", i, sp.Alphabet)
+		}
+	}
+	return nil
+}
+
+/**
+ * checkWordCounts validates word count settings for New and the setters
+ * @param words int exact count
+ * @param min int range lower bound
+ * @param max int range upper bound zero means unbounded by itself
+ * @return error for negative values or a max below min
+ */
+func checkWordCounts(words, min, max int) error {
+	if words < 0 || min < 0 || max < 0 {
+		return fmt.Errorf("word counts must not be negative (Words %d MinWords %d MaxWords %d)", words, min, max)
+	}
+	if max > 0 && max < min {
+		return fmt.Errorf("MaxWords %d is below MinWords %d", max, min)
+	}
+	return nil
+}
+
+/**
+ * Detail is the result of GenerateDetailed
+ * Words is the word count actually used for Name after range randomization
+ */
+type Detail struct {
+	Name  string
+	Words int
+}
+
+/**
+ * GenerateDetailed generates a name and reports the word count it used
+ * safe for concurrent use the count belongs to this call only
  * @param nWords int optional override for number of words
- * @return int number of bytes written and error if any
+ * @return Detail generated name and its word count
  */
-func (g *Generator) WriteTo(w io.Writer, nWords int) (int, error) {
-	buf := make([]byte, 0, 64) // small stack buffer
-	buf = g.GenerateInto(buf, nWords)
-	return w.Write(buf) // writer may allocate but this function does not
+func (g *Generator) GenerateDetailed(nWords int) Detail {
+	if len(g.lists) == 0 || !g.takeQuota() {
+		return Detail{}
+	}
+	count := 0
+	name := pooledString(func(dst []byte) []byte {
+		dst, _ = g.buildFor(dst, func() int {
+			count = g.wordCount(nWords)
+			return count
+		})
+		return dst
+	})
+	return Detail{Name: name, Words: count}
+}
+
+/**
+ * ListInfo describes one list a generator draws from
+ * ID is a directory such as adjectives under MergeByDir or a file path under MergeByFile
+ */
+type ListInfo struct {
+	ID    string
+	Words int
+}
+
+/**
+ * Lists reports the lists in the order word positions cycle through them
+ * handy for checking at startup that the include globs picked the expected buckets
+ * @return []ListInfo id and word count per list
+ */
+func (g *Generator) Lists() []ListInfo {
+	out := make([]ListInfo, len(g.lists))
+	for i, l := range g.lists {
+		out[i] = ListInfo{ID: g.ids[i], Words: len(l)}
+	}
+	return out
+}
+
+/**
+ * Word draws one random word from the list with the given id
+ * ids are those New built such as adjectives under MergeByDir or a file path under MergeByFile
+ * a word that ReservedWords or Reserve holds as a whole name is redrawn
+ * @param listID string list id
+ * @return string word and error when the id is unknown or ErrExhausted when every redraw was rejected
+ */
+func (g *Generator) Word(listID string) (string, error) {
+	for i, id := range g.ids {
+		if id == listID {
+			ent := entropyBuf{src: g.entropy}
+			for try := 0; try < maxRedraws; try++ {
+				g.lock()
+				w := g.pickWord(i, g.weights != nil, &ent)
+				g.unlock()
+				if !g.isHeld([]byte(w)) && !g.isReserved([]byte(w)) {
+					return w, nil
+				}
+			}
+			return "", ErrExhausted
+		}
+	}
+	return "", fmt.Errorf("unknown list %q", listID)
+}
+
+/**
+ * Contains reports whether word exists in any of the generator lists
+ * words are compared after the same case normalization the lists went through
+ * the lookup set is built once on the first call
+ * @param word string candidate word
+ * @return bool true when some list holds the word
+ */
+func (g *Generator) Contains(word string) bool {
+	g.memberOnce.Do(func() {
+		g.members = make(map[string]struct{})
+		for _, l := range g.lists {
+			for _, w := range l {
+				g.members[g.memberKey(w)] = struct{}{}
+			}
+		}
+	})
+	_, ok := g.members[g.memberKey(word)]
+	return ok
+}
+
+/**
+ * memberKey is the form a word takes in the membership set
+ * @param w string word
+ * @return string key
+ */
+func (g *Generator) memberKey(w string) string {
+	if g.foldCase {
+		return strings.ToLower(w)
+	}
+	return w
+}
+
+/**
+ * Position reports how many names this generator has built so far
+ * it counts raw draws so names redrawn by the held ReservedWords AvoidCommonPrefix
+ * and MinDistance checks count too and it runs ahead of the names returned
+ * once any check rejects one
+ * a logged position plus the seed reproduces a name only on a generator with
+ * the same options checks and held names replaying every draw made before it
+ * @return uint64 number of draws including rejected ones
+ */
+func (g *Generator) Position() uint64 {
+	return atomic.LoadUint64(&g.position)
+}
+
+/**
+ * Skip advances the generator past n names without returning them
+ * the source has no jump ahead so each name is built into a scratch buffer
+ * through the same checks and redraws as Generate which keeps words counters
+ * and seeded slugs in step with real generation the quota is not charged
+ * n counts returned names not draws so under checks Skip(n) moves Position by
+ * at least n and replaying a logged Position needs the accepted count instead
+ * @param n int number of names to skip non positive is a no op
+ * @return void
+ */
+func (g *Generator) Skip(n int) {
+	if len(g.lists) == 0 {
+		return
+	}
+	var scratch [128]byte
+	for i := 0; i < n; i++ {
+		g.buildFor(scratch[:0], func() int { return g.wordCount(0) })
+	}
+}
+
+/**
+ * wordCount resolves the number of words for one call
+ * a positive override wins then the exact setting then the configured range
+ * slug only generators always use zero words
+ * @param nWords int optional override for number of words
+ * @return int word count of at least one or zero in slug only mode
+ */
+func (g *Generator) wordCount(nWords int) int {
+	if g.slugOnly {
+		return 0
+	}
+	count := nWords
+	if count <= 0 {
+		r, shard := g.acquire()
+		if count = g.wordsExact; count <= 0 {
+			count = g.drawWordCount(r)
+		}
+		g.release(shard)
+	}
+	if count <= 0 {
+		count = 1
+	}
+	return count
+}
+
+/**
+ * build writes a name with exactly count words into dst
+ * a failure such as an exhausted counter is reported alongside a best effort name
+ * @param dst []byte destination buffer provided by the caller
+ * @param count int number of words already decided
+ * @return []byte slice containing the generated name and error if any
+ */
+func (g *Generator) build(dst []byte, count int) ([]byte, error) {
+	var err error
+	pos := atomic.AddUint64(&g.position, 1) - 1
+
+	// draw every word up front under one lock so sizing and output agree
+	// crypto bytes for words and slug come from one buffer per name
+	ent := entropyBuf{src: g.entropy}
+	var stack [maxStackWords]string
+	words := stack[:0]
+	r, shard := g.acquire()
+	ent.rng = r
+	for i := 0; i < count; i++ {
+		li := i % len(g.lists)
+		w := g.pickWord(li, g.weighted(i), &ent)
+		for try := 0; g.noRepeat && i > 0 && w == words[i-1] && try < repeatTries; try++ {
+			w = g.pickWord(li, g.weighted(i), &ent)
+		}
+		words = append(words, w)
+	}
+	useSlug, useCounter := g.suffixes(pos, r)
+	g.release(shard)
+
+	// splice FixedWords literals in and track which list each position came from
+	var src []int
+	if g.fixed != nil && count > 0 {
+		var out [maxStackWords]string
+		var from [maxStackWords]int
+		words, src = g.interleave(words, out[:0], from[:0])
+		count = len(words)
+	}
+
+	// compute final length to size buffer correctly
+	totalLen := 0
+	for i, w := range words {
+		totalLen += len(w)
+		if g.softHyphen > 0 {
+			totalLen += hyphens(w, g.softHyphen)
+		}
+		if g.phraseJoin && len(g.delim) > 1 {
+			totalLen += phraseSpaces(w) * (len(g.delim) - 1)
+		}
+		if id, ok := g.idAt(src, i); ok {
+			totalLen += len(id) + 1 // id plus separator
+		}
+	}
+	bare := g.bareJoin(useSlug)
+	if count > 1 && !g.noDelim {
+		boundary := len(g.delim)
+		if bare || len(g.wordDelims) > 0 {
+			boundary = 1
+		}
+		totalLen += (count - 1) * boundary // delimiter bytes per word boundary
+	}
+	if useSlug {
+		totalLen += len(g.suffixDelim()) + groupedLen(g.slugLen, g.slugGroup) // delimiter plus slug bytes and group separators
+	}
+	if useCounter {
+		totalLen += len(g.suffixDelim()) + g.counterWidth // delimiter plus counter digits
+	}
+	totalLen += g.specLen // extra slugs with one delimiter each
+	if count == 0 && totalLen > 0 {
+		totalLen -= g.leadDelimLen(useSlug) // suffix only names have no leading delimiter
+	}
+
+	// ensure capacity without allocating if caller provided enough space
+	if cap(dst) < totalLen {
+		// fall back to allocation only if caller did not give enough space
+		dst = make([]byte, 0, totalLen)
+	} else {
+		dst = dst[:0]
+	}
+
+	// build words into dst with any extra slugs placed before or between them
+	if g.slugSpecs != nil {
+		dst = g.appendSpecs(dst, SlugPrefix, 0, 0, &ent)
+	}
+	for i, w := range words {
+		if i > 0 && g.noDelim {
+			// concatenated words get no boundary byte
+		} else if i > 0 {
+			if bare {
+				dst = append(dst, g.bareDelim)
+			} else {
+				dst = append(dst, g.wordDelim(i-1)...)
+			}
+		} else if len(dst) > 0 {
+			dst = append(dst, g.delim...)
+		}
+		if id, ok := g.idAt(src, i); ok {
+			dst = append(dst, id...)
+			dst = append(dst, listIDSep)
+		}
+		dst = g.appendWord(dst, w)
+		if g.slugSpecs != nil {
+			dst = g.appendSpecs(dst, SlugAfterWord, i, count-1, &ent)
+		}
+	}
+
+	// append slug directly into dst no temp slice
+	wordEnd := len(dst)
+	if useSlug {
+		if len(dst) > 0 {
+			dst = append(dst, g.suffixDelim()...)
+		}
+		start := len(dst)
+		alphabet := g.slugAlphabet
+		if alphabet == nil {
+			alphabet = base32
+		}
+		dst = g.appendSlug(dst, g.slugLen, alphabet, &ent)
+		if g.slugGroup > 0 {
+			dst = groupSlug(dst, start, g.slugGroup, g.slugGroupSep)
+		}
+		if g.upperSlug {
+			upperASCII(dst[start:])
+		}
+	}
+
+	if g.slugSpecs != nil {
+		dst = g.appendSpecs(dst, SlugSuffix, 0, 0, &ent)
+	}
+
+	// append the permuted counter last so every name stays unique
+	if useCounter {
+		if len(dst) > 0 {
+			dst = append(dst, g.suffixDelim()...)
+		}
+		start := len(dst)
+		dst, err = g.appendCounter(dst)
+		if g.upperSlug {
+			upperASCII(dst[start:])
+		}
+	}
+
+	if g.maxLen > 0 && len(dst) > g.maxLen {
+		dst = g.capLen(dst, wordEnd)
+	}
+	if ent.err != nil && g.entropyFail == EntropyFailError && err == nil {
+		err = fmt.Errorf("%w: %v", ErrEntropy, ent.err)
+	}
+	return dst, err
+}
+
+/**
+ * interleave places the FixedWords literals among the sampled words
+ * a literal takes its position and pushes later words back while sampled words
+ * keep their list assignment a literal is kept once every position before it
+ * is filled so {2: x} follows a two word name but {5: x} is left out
+ * @param words []string sampled words in list order
+ * @param out []string scratch space for the merged words
+ * @param src []int scratch space for the list index of each position
+ * @return []string merged words and []int list index per position minus one for literals
+ */
+func (g *Generator) interleave(words, out []string, src []int) ([]string, []int) {
+	for p, j := 0, 0; ; p++ {
+		if w, ok := g.fixed[p]; ok {
+			out = append(out, w)
+			src = append(src, -1)
+			continue
+		}
+		if j == len(words) {
+			return out, src
+		}
+		out = append(out, words[j])
+		src = append(src, j%len(g.lists))
+		j++
+	}
+}
+
+/**
+ * idAt returns the list id PrefixListIDs writes before the word at position i
+ * @param src []int list index per position from interleave nil when there are no literals
+ * @param i int word position
+ * @return string id and bool false when no id is written
+ */
+func (g *Generator) idAt(src []int, i int) (string, bool) {
+	if !g.prefixIDs {
+		return "", false
+	}
+	if src == nil {
+		return g.ids[i%len(g.ids)], true
+	}
+	if src[i] < 0 {
+		return "", false
+	}
+	return g.ids[src[i]], true
+}
+
+/**
+ * suffixes decides which configured suffixes this name carries
+ * SlugProbability and SuffixRandom draw from r so the caller must hold it from acquire
+ * @param pos uint64 zero based generation index
+ * @param r *rand.Rand rng to draw from
+ * @return bool slug and bool counter
+ */
+func (g *Generator) suffixes(pos uint64, r *rand.Rand) (bool, bool) {
+	slug, counter := g.slugLen > 0, g.counterWidth > 0
+	if slug && g.slugProb > 0 && g.slugProb < 1 {
+		slug = r.Float64() < g.slugProb
+	}
+	if !slug || !counter {
+		return slug, counter
+	}
+	switch g.suffix {
+	case SuffixSlug:
+		return true, false
+	case SuffixCounter:
+		return false, true
+	case SuffixAlternate:
+		return pos%2 == 1, pos%2 == 0
+	case SuffixRandom:
+		first := r.Intn(2) == 0
+		return first, !first
+	}
+	return true, true
+}
+
+/**
+ * suffixDelim returns the delimiter before the slug and counter suffix
+ * @return []byte SlugDelimiter when set otherwise delim
+ */
+func (g *Generator) suffixDelim() []byte {
+	if g.slugDelim != nil {
+		return g.slugDelim
+	}
+	return g.delim
+}
+
+/**
+ * bareJoin reports whether the words of a name are joined by NoSlugDelimiter
+ * @param useSlug bool whether this name carries the SlugLength slug
+ * @return bool true for a name without the slug or extra slugs while NoSlugDelimiter is set
+ */
+func (g *Generator) bareJoin(useSlug bool) bool {
+	return !useSlug && g.slugSpecs == nil && g.bareDelim != 0
+}
+
+/**
+ * slugOnEvery reports whether every name build writes carries the SlugLength slug
+ * @return bool false without a slug or when SlugProbability or the suffix strategy skips it
+ */
+func (g *Generator) slugOnEvery() bool {
+	if g.slugLen <= 0 || g.slugProb > 0 && g.slugProb < 1 {
+		return false
+	}
+	return g.counterWidth <= 0 || g.suffix == SuffixBoth || g.suffix == SuffixSlug
+}
+
+/**
+ * leadDelimLen is the delimiter a suffix only name leaves off its first part
+ * extra slugs lead with delim while the slug and counter lead with slugDelim
+ * @param useSlug bool whether this name carries the SlugLength slug
+ * @return int bytes of the delimiter that would have opened the name
+ */
+func (g *Generator) leadDelimLen(useSlug bool) int {
+	for _, sp := range g.slugSpecs {
+		if sp.pos == SlugPrefix || !useSlug && sp.pos == SlugSuffix {
+			return len(g.delim)
+		}
+	}
+	return len(g.suffixDelim())
+}
+
+/**
+ * capLen cuts a name over the length cap inside its words so the slug extra
+ * slugs and counter from wordEnd on survive whole and a counter never repeats
+ * a suffix that leaves no room for a word falls back to truncate
+ * @param dst []byte name longer than the cap
+ * @param wordEnd int offset where the suffix after the words starts
+ * @return []byte capped name
+ */
+func (g *Generator) capLen(dst []byte, wordEnd int) []byte {
+	suffix := len(dst) - wordEnd
+	n := g.maxLen - suffix
+	if wordEnd == 0 || n <= 0 {
+		return g.truncate(dst)
+	}
+	for n > 0 && !utf8.RuneStart(dst[n]) {
+		n--
+	}
+	for n > 0 && g.isDelim(dst[n-1]) {
+		n--
+	}
+	if n == 0 {
+		return g.truncate(dst)
+	}
+	return append(dst[:n], dst[wordEnd:]...)
+}
+
+/**
+ * suffixMax is the longest suffix build appends after the words
+ * @param counterWidth int counter digits zero when there is no counter
+ * @return int bytes of the slug suffix slugs and counter with their delimiters
+ */
+func (g *Generator) suffixMax(counterWidth int) int {
+	n := 0
+	if g.slugLen > 0 {
+		n += len(g.suffixDelim()) + groupedLen(g.slugLen, g.slugGroup)
+	}
+	for _, sp := range g.slugSpecs {
+		if sp.pos == SlugSuffix {
+			n += len(g.delim) + sp.n
+		}
+	}
+	if counterWidth > 0 {
+		n += len(g.suffixDelim()) + counterWidth
+	}
+	return n
+}
+
+/**
+ * truncate cuts the name to the length cap on a rune boundary
+ * and drops any delimiters left dangling at the end
+ * @param dst []byte name longer than the cap
+ * @return []byte truncated name
+ */
+func (g *Generator) truncate(dst []byte) []byte {
+	n := g.maxLen
+	for n > 0 && !utf8.RuneStart(dst[n]) {
+		n--
+	}
+	dst = dst[:n]
+	for len(dst) > 0 && g.isDelim(dst[len(dst)-1]) {
+		dst = dst[:len(dst)-1]
+	}
+	return dst
+}
+
+/**
+ * isDelim reports whether c is a delimiter byte or one of the word delimiters
+ * @param c byte candidate
+ * @return bool true for any configured delimiter byte
+ */
+func (g *Generator) isDelim(c byte) bool {
+	return bytes.IndexByte(g.delim, c) >= 0 || bytes.IndexByte(g.slugDelim, c) >= 0 || bytes.IndexByte(g.wordDelims, c) >= 0
+}
+
+/**
+ * appendWord appends one chosen word applying phrase joining and casing in place
+ * no temporary strings are built so the zero allocation path holds
+ * @param dst []byte destination buffer
+ * @param w string chosen word
+ * @return []byte the destination buffer with the word appended
+ */
+func (g *Generator) appendWord(dst []byte, w string) []byte {
+	start := len(dst)
+	if g.upperRunes {
+		for _, r := range w {
+			dst = utf8.AppendRune(dst, unicode.ToUpper(r))
+		}
+	} else {
+		dst = append(dst, w...)
+		switch g.wordCase {
+		case CaseTitle:
+			upperASCII(dst[start:min(start+1, len(dst))])
+		case CaseUpper:
+			upperASCII(dst[start:])
+		case CaseLower:
+			lowerASCII(dst[start:])
+		}
+		if g.upper {
+			upperASCII(dst[start:])
+		}
+	}
+
+	// rewrite phrase spaces in place so "north star" fills one slot as north_star
+	if g.phraseJoin && len(g.delim) == 1 {
+		for j := start; j < len(dst); j++ {
+			if dst[j] == ' ' || dst[j] == '\t' {
+				dst[j] = g.delim[0]
+			}
+		}
+	} else if g.phraseJoin {
+		dst = joinPhrase(dst, start, g.delim)
+	}
+	if g.softHyphen > 0 {
+		dst = softHyphenate(dst, start, g.softHyphen)
+	}
+	return dst
+}
+
+/**
+ * phraseSpaces counts the spaces and tabs PhraseJoin rewrites in w
+ * @param w string chosen word
+ * @return int blanks in the phrase
+ */
+func phraseSpaces(w string) int {
+	return strings.Count(w, " ") + strings.Count(w, "\t")
+}
+
+/**
+ * joinPhrase swaps each blank in dst[start:] for a multi byte delimiter
+ * in place from the back like softHyphenate so no scratch buffer is needed
+ * @param dst []byte buffer whose tail is the phrase
+ * @param start int index where the phrase begins
+ * @param delim []byte delimiter longer than one byte
+ * @return []byte the buffer with the joined phrase
+ */
+func joinPhrase(dst []byte, start int, delim []byte) []byte {
+	blanks := 0
+	for _, c := range dst[start:] {
+		if c == ' ' || c == '\t' {
+			blanks++
+		}
+	}
+	if blanks == 0 {
+		return dst
+	}
+	end := len(dst)
+	for i := blanks * (len(delim) - 1); i > 0; i-- {
+		dst = append(dst, 0)
+	}
+	w := len(dst)
+	for r := end - 1; r >= start; r-- {
+		if c := dst[r]; c == ' ' || c == '\t' {
+			w -= len(delim)
+			copy(dst[w:], delim)
+		} else {
+			w--
+			dst[w] = c
+		}
+	}
+	return dst
+}
+
+/**
+ * hyphens counts the hyphens SoftHyphenate adds to w
+ * @param w string chosen word
+ * @param n int runes per piece
+ * @return int one per piece boundary zero for words of n runes or fewer
+ */
+func hyphens(w string, n int) int {
+	if r := utf8.RuneCountInString(w); r > n {
+		return (r - 1) / n
+	}
+	return 0
+}
+
+/**
+ * softHyphenate breaks the word at dst[start:] with a hyphen every n runes
+ * in place from the back so no scratch buffer is needed
+ * @param dst []byte buffer whose tail is the word
+ * @param start int index where the word begins
+ * @param n int runes per piece
+ * @return []byte the buffer with the hyphenated word
+ */
+func softHyphenate(dst []byte, start, n int) []byte {
+	runes := utf8.RuneCount(dst[start:])
+	if runes <= n {
+		return dst
+	}
+	end := len(dst)
+	for i := (runes - 1) / n; i > 0; i-- {
+		dst = append(dst, 0)
+	}
+	w := len(dst)
+	for i, e := runes-1, end; i >= 0; i-- {
+		s := e - 1
+		for s > start && !utf8.RuneStart(dst[s]) {
+			s--
+		}
+		w -= e - s
+		copy(dst[w:], dst[s:e])
+		if i > 0 && i%n == 0 {
+			w--
+			dst[w] = '-'
+		}
+		e = s
+	}
+	return dst
+}
+
+/**
+ * wordDelim returns the delimiter for the boundary after word i
+ * @param i int zero based boundary index
+ * @return []byte delimiter bytes sliced from the generator never modify them
+ */
+func (g *Generator) wordDelim(i int) []byte {
+	if len(g.wordDelims) == 0 {
+		return g.delim
+	}
+	i %= len(g.wordDelims)
+	return g.wordDelims[i : i+1]
+}
+
+/**
+ * upperASCII uppercases ascii letters in place leaving other bytes alone
+ * @param b []byte bytes to rewrite
+ * @return void
+ */
+func upperASCII(b []byte) {
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' {
+			b[i] = c - ('a' - 'A')
+		}
+	}
+}
+
+/**
+ * lowerASCII lowercases ascii letters of b in place
+ * @param b []byte bytes to rewrite
+ * @return void
+ */
+func lowerASCII(b []byte) {
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+}
+
+/**
+ * appendSlug appends a random slug of n symbols from alphabet
+ * degenerate slugs are redrawn a bounded number of times when asked
+ * @param dst []byte destination buffer
+ * @param n int slug length
+ * @param alphabet []byte symbols to draw from
+ * @param ent *entropyBuf crypto bytes shared across this name
+ * @return []byte the destination buffer with the slug appended
+ */
+func (g *Generator) appendSlug(dst []byte, n int, alphabet []byte, ent *entropyBuf) []byte {
+	if !g.rejectDegenerate {
+		return g.drawSlug(dst, n, alphabet, ent)
+	}
+	start := len(dst)
+	for try := 0; ; try++ {
+		dst = g.drawSlug(dst, n, alphabet, ent)
+		if try == maxSlugRedraws || !isDegenerateSlug(dst[start:]) {
+			return dst
+		}
+		// a redraw starts from a fresh read so a stuck buffer is not reused
+		ent.discard()
+		dst = dst[:start]
+	}
+}
+
+/**
+ * drawSlug appends one slug from the configured source
+ * @param dst []byte destination buffer
+ * @param n int slug length
+ * @param alphabet []byte symbols to draw from
+ * @param ent *entropyBuf crypto bytes shared across this name
+ * @return []byte the destination buffer with the slug appended
+ */
+func (g *Generator) drawSlug(dst []byte, n int, alphabet []byte, ent *entropyBuf) []byte {
+	if g.slugStream != nil {
+		g.lock()
+		dst = appendSlugStream(dst, n, alphabet, g.slugStream)
+		g.unlock()
+		return dst
+	}
+	return ent.appendSlug(dst, n, alphabet)
+}
+
+/**
+ * appendSpecs appends every extra slug placed at pos in spec order
+ * each one is preceded by the delimiter unless it opens the name
+ * @param dst []byte destination buffer
+ * @param pos SlugPosition placement being written
+ * @param word int word index for SlugAfterWord clamped to the last word
+ * @param last int index of the last word
+ * @param ent *entropyBuf crypto bytes shared across this name
+ * @return []byte the destination buffer with the slugs appended
+ */
+func (g *Generator) appendSpecs(dst []byte, pos SlugPosition, word, last int, ent *entropyBuf) []byte {
+	for _, sp := range g.slugSpecs {
+		if sp.pos != pos || pos == SlugAfterWord && min(sp.after, last) != word {
+			continue
+		}
+		if len(dst) > 0 {
+			dst = append(dst, g.delim...)
+		}
+		start := len(dst)
+		dst = g.appendSlug(dst, sp.n, sp.alphabet, ent)
+		if g.upperSlug {
+			upperASCII(dst[start:])
+		}
+	}
+	return dst
+}
+
+/**
+ * appendCounter takes the next counter value and appends its permuted encoding
+ * the counter wraps after every value in the space has been used once
+ * @param dst []byte destination buffer
+ * @return []byte the destination buffer with the counter appended and error once wrapped or unsaved
+ */
+func (g *Generator) appendCounter(dst []byte) ([]byte, error) {
+	n := atomic.AddUint64(&g.counter, 1) - 1
+	v := g.perm.permute(n % g.perm.limit)
+	dst = appendBase32Fixed(dst, v, g.counterWidth)
+	if n >= g.perm.limit {
+		return dst, ErrExhausted
+	}
+	return dst, g.reserveCounter(n)
+}
+
+/**
+ * Generate is a convenience wrapper that returns a string
+ * borrows a pooled buffer for the build so only the string copy allocates
+ * errors are ignored and the best effort name is returned see GenerateE
+ * @param nWords int optional override for number of words
+ * @return string generated name
+ */
+func (g *Generator) Generate(nWords int) string {
+	s, _ := g.GenerateE(nWords)
+	return s
+}
+
+/**
+ * GenerateE is Generate with an error channel
+ * reports ErrNoLists for an empty generator and ErrExhausted once a never repeating
+ * suffix has used its whole space the string is still a best effort name then
+ * @param nWords int optional override for number of words
+ * @return string generated name and error if any
+ */
+func (g *Generator) GenerateE(nWords int) (string, error) {
+	var err error
+	s := pooledString(func(dst []byte) []byte {
+		dst, err = g.GenerateIntoE(dst, nWords)
+		return dst
+	})
+	return s, err
+}
+
+/**
+ * GenerateN generates n names in one call reusing a single scratch buffer
+ * the names are packed into one backing string so the batch costs a handful of
+ * allocations instead of one per name and holding any name keeps the batch alive
+ * names are independent draws so duplicates are possible unless a counter suffix
+ * or a similar uniqueness option rules them out failed draws such as an
+ * exceeded quota leave an empty string
+ * @param n int number of names zero or less gives an empty slice
+ * @param nWords int optional override for number of words
+ * @return []string freshly allocated names of length n
+ */
+func (g *Generator) GenerateN(n, nWords int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+	out := make([]string, n)
+	ends := make([]int, n)
+	buf := make([]byte, 0, 64)
+	var arena strings.Builder
+	for i := range out {
+		buf = g.GenerateInto(buf[:0], nWords)
+		if i == 0 {
+			arena.Grow(n * (len(buf) + len(buf)/2 + 1)) // size the batch from the first name
+		}
+		arena.Write(buf)
+		ends[i] = arena.Len()
+	}
+	all := arena.String()
+	start := 0
+	for i, end := range ends {
+		out[i] = all[start:end]
+		start = end
+	}
+	return out
+}
+
+/**
+ * pooledString runs fill on a pooled buffer and returns the bytes as a string
+ * the string copy is the only allocation when the buffer is large enough
+ * @param fill func([]byte) []byte writes a name into the given empty buffer
+ * @return string copy of the written bytes
+ */
+func pooledString(fill func(dst []byte) []byte) string {
+	bp := namePool.Get().(*[]byte)
+	b := fill((*bp)[:0])
+	s := string(b) // the only allocation the string copy
+
+	// keep the grown buffer when reasonable so the next call reuses it
+	if cap(b) <= maxPooledBuf {
+		*bp = b[:0]
+		namePool.Put(bp)
+	}
+	return s
+}
+
+/**
+ * weighted reports whether word position i samples by length weights
+ * positions past PositionSampling follow LengthBias
+ * @param i int zero based word position
+ * @return bool true for weighted sampling
+ */
+func (g *Generator) weighted(i int) bool {
+	if i < len(g.posWeighted) {
+		return g.posWeighted[i]
+	}
+	return g.weights != nil
+}
+
+/**
+ * pickWord draws one word from list li caller must hold the rng lock
+ * uniform unless weighted is set from crypto rand with CryptoWords and
+ * downweighted by recent use under RecencyCooldown
+ * @param li int list index
+ * @param weighted bool sample by the precomputed length weights
+ * @param ent *entropyBuf crypto bytes shared across this name
+ * @return string chosen word
+ */
+func (g *Generator) pickWord(li int, weighted bool, ent *entropyBuf) string {
+	return g.lists[li][g.pickAt(li, weighted, ent)]
+}
+
+/**
+ * pickAt is pickWord returning the word index caller must hold the rng lock
+ * @param li int list index
+ * @param weighted bool sample by the precomputed length weights
+ * @param ent *entropyBuf crypto bytes shared across this name
+ * @return int word index
+ */
+func (g *Generator) pickAt(li int, weighted bool, ent *entropyBuf) int {
+	if g.cooldown > 0 {
+		return g.pickCooled(li, weighted, ent)
+	}
+	return g.pickIndex(li, weighted, ent)
+}
+
+/**
+ * pickIndex draws the index of one word from list li caller must hold the rng lock
+ * @param li int list index
+ * @param weighted bool use the LengthBias weights
+ * @param ent *entropyBuf entropy used under CryptoWords
+ * @return int word index
+ */
+func (g *Generator) pickIndex(li int, weighted bool, ent *entropyBuf) int {
+	n := len(g.lists[li])
+	if !weighted {
+		if g.cryptoWords {
+			return ent.intn(n)
+		}
+		return g.rngFor(ent).Intn(n)
+	}
+	cum := g.weights[li]
+	x := g.unit(ent) * cum[len(cum)-1]
+	return sort.Search(len(cum)-1, func(i int) bool { return cum[i] > x })
+}
+
+/**
+ * unit draws a float in [0,1) from the source words use
+ * @param ent *entropyBuf entropy used under CryptoWords
+ * @return float64 uniform value
+ */
+func (g *Generator) unit(ent *entropyBuf) float64 {
+	if g.cryptoWords {
+		return ent.float64()
+	}
+	return g.rngFor(ent).Float64()
+}
+
+// repeatTries bounds redraws under NoAdjacentRepeat so lists that hold only
+// the repeated word keep it instead of looping
+const repeatTries = 8
+
+// cooldownTries bounds redraws of recently used words so a list smaller than
+// RecencyCooldown still yields a word
+const cooldownTries = 8
+
+/**
+ * pickCooled draws an index under RecencyCooldown a word picked age draws ago
+ * is kept with odds age/(cooldown+1) so its weight recovers as it ages
+ * ticks cost one uint64 per word and are only allocated for lists in use
+ * @param li int list index
+ * @param weighted bool use the LengthBias weights
+ * @param ent *entropyBuf entropy used under CryptoWords
+ * @return int word index
+ */
+func (g *Generator) pickCooled(li int, weighted bool, ent *entropyBuf) int {
+	if g.lastUse == nil {
+		g.lastUse = make([][]uint64, len(g.lists))
+		g.ticks = make([]uint64, len(g.lists))
+	}
+	last := g.lastUse[li]
+	if last == nil {
+		last = make([]uint64, len(g.lists[li]))
+		g.lastUse[li] = last
+	}
+	g.ticks[li]++
+	now := g.ticks[li]
+	span := uint64(g.cooldown) + 1
+
+	j := 0
+	for try := 0; try < cooldownTries; try++ {
+		j = g.pickIndex(li, weighted, ent)
+		age := now - last[j]
+		if last[j] == 0 || age >= span || g.unit(ent)*float64(span) < float64(age) {
+			break
+		}
+	}
+	last[j] = now
+	return j
+}
+
+/**
+ * lengthWeights builds cumulative selection weights of runes^bias for every list
+ * a negative bias favors short words and a positive one long words
+ * @param lists [][]string merged lists
+ * @param bias float64 exponent applied to each word length
+ * @return [][]float64 running totals aligned with each list
+ */
+func lengthWeights(lists [][]string, bias float64) [][]float64 {
+	out := make([][]float64, len(lists))
+	for i, list := range lists {
+		cum := make([]float64, len(list))
+		total := 0.0
+		for j, w := range list {
+			total += math.Pow(float64(utf8.RuneCountInString(w)), bias)
+			cum[j] = total
+		}
+		out[i] = cum
+	}
+	return out
+}
+
+/**
+ * randWordCount picks a word count using min and max bounds
+ * returns an (old) docker like default of two when bounds are not set
+ * @return int chosen word count
+ */
+func (g *Generator) randWordCount() int {
+	g.lock()
+	n := g.drawWordCount(g.rng)
+	g.unlock()
+	return n
+}
+
+/**
+ * drawWordCount is randWordCount for callers holding the rng from acquire
+ * @param r *rand.Rand rng to draw from
+ * @return int chosen word count
+ */
+func (g *Generator) drawWordCount(r *rand.Rand) int {
+	if len(g.allowedCounts) > 0 {
+		return g.allowedCounts[r.Intn(len(g.allowedCounts))]
+	}
+	if g.minWords <= 0 && g.maxWords <= 0 {
+		return 2
+	}
+	return drawRange(r, g.minWords, g.maxWords)
+}
+
+/**
+ * randRange draws a word count uniformly from min to max inclusive
+ * min below one becomes one and max below min collapses to min
+ * @param min int inclusive lower bound
+ * @param max int inclusive upper bound
+ * @return int chosen word count
+ */
+func (g *Generator) randRange(min, max int) int {
+	r, shard := g.acquire()
+	n := drawRange(r, min, max)
+	g.release(shard)
+	return n
+}
+
+/**
+ * drawRange is randRange for callers holding the rng from acquire
+ * @param r *rand.Rand rng to draw from
+ * @param min int inclusive lower bound
+ * @param max int inclusive upper bound
+ * @return int chosen word count
+ */
+func drawRange(r *rand.Rand, min, max int) int {
+	if min <= 0 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return r.Intn(max-min+1) + min
+}
+
+/**
+ * lock takes the rng mutex unless the generator is single threaded
+ * @return void
+ */
+func (g *Generator) lock() {
+	if !g.singleThreaded {
+		g.rngMu.Lock()
+	}
+}
+
+/**
+ * unlock releases the rng mutex taken by lock
+ * @return void
+ */
+func (g *Generator) unlock() {
+	if !g.singleThreaded {
+		g.rngMu.Unlock()
+	}
+}
+
+/**
+ * acquire hands out the rng for one draw and the shard to give back to release
+ * the shared rng is taken whenever its lock is free so one goroutine sees the
+ * exact seeded sequence while a contended call draws from a pooled shard seeded
+ * from Seed and a shard counter giving up determinism instead of waiting
+ * a shard holds the word shape read lock so SetWords cannot race its reads
+ * @return *rand.Rand rng to draw from and *rand.Rand shard nil when the lock is held
+ */
+func (g *Generator) acquire() (*rand.Rand, *rand.Rand) {
+	switch {
+	case g.singleThreaded:
+		return g.rng, nil
+	case g.rngMu.TryLock():
+		return g.rng, nil
+	case g.shards == nil:
+		g.rngMu.Lock()
+		return g.rng, nil
+	}
+	g.shapeMu.RLock()
+	r := g.shards.Get().(*rand.Rand)
+	return r, r
+}
+
+/**
+ * release returns what acquire handed out
+ * @param shard *rand.Rand shard from acquire nil releases the rng lock
+ * @return void
+ */
+func (g *Generator) release(shard *rand.Rand) {
+	if shard == nil {
+		g.unlock()
+		return
+	}
+	g.shards.Put(shard)
+	g.shapeMu.RUnlock()
+}
+
+/**
+ * rngFor picks the rng a word draw uses the one ent carries from acquire or g.rng
+ * @param ent *entropyBuf per name draw state
+ * @return *rand.Rand rng to draw from
+ */
+func (g *Generator) rngFor(ent *entropyBuf) *rand.Rand {
+	if ent.rng != nil {
+		return ent.rng
+	}
+	return g.rng
+}
+
+/**
+ * newShards builds the pool of contention shards for a generator
+ * each shard is seeded from seed mixed with its own index
+ * @param seed int64 normalized Seed
+ * @param stable bool draw from the splitmix64 source
+ * @return *sync.Pool pool of *rand.Rand
+ */
+func newShards(seed int64, stable bool) *sync.Pool {
+	var n uint64
+	return &sync.Pool{New: func() any {
+		i := atomic.AddUint64(&n, 1)
+		return newRand(int64(mix64(uint64(seed)+i)), stable)
+	}}
+}
+
+/**
+ * WriteTo writes a generated name to an io Writer
+ * borrows a pooled scratch buffer so repeated writes do not allocate
+ * the name goes out in a single Write call
+ * @param w io.Writer destination writer
+ * @param nWords int optional override for number of words
+ * @return int number of bytes written and error if any
+ */
+func (g *Generator) WriteTo(w io.Writer, nWords int) (int, error) {
+	bp := namePool.Get().(*[]byte)
+	buf := g.GenerateInto((*bp)[:0], nWords)
+	n, err := w.Write(buf) // writers must not retain buf so it can go back to the pool
+
+	if cap(buf) <= maxPooledBuf {
+		*bp = buf[:0]
+		namePool.Put(bp)
+	}
+	return n, err
+}
+
+/**
+ * Stream sends generated names on the returned channel until ctx is done
+ * a producer goroutine reuses one buffer across GenerateIntoE calls and checks
+ * ctx both before each name and while waiting for a receiver so it never leaks
+ * the channel is closed on cancellation or once a name fails such as with
+ * ErrQuotaExceeded or ErrExhausted so a range loop ends either way
+ * @param ctx context.Context cancels the stream
+ * @param nWords int optional override for number of words
+ * @return <-chan string unbuffered names
+ */
+func (g *Generator) Stream(ctx context.Context, nWords int) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		buf := make([]byte, 0, 64)
+		for ctx.Err() == nil {
+			var err error
+			if buf, err = g.GenerateIntoE(buf[:0], nWords); err != nil {
+				return
+			}
+			select {
+			case ch <- string(buf):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
 }
@@ -1,11 +1,23 @@
 package namemachine
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
 	"path"
+	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 /**
@@ -90,6 +102,7 @@ func TestEmbeddedFilesPresentAndNonEmpty(t *testing.T) {
 /**
  * TestNoDuplicatesWithinEachFile enforces that a single list file has no duplicate tokens
  * cross file duplicates are allowed this test is per file only
+ * bundled lists stay duplicate free user lists may repeat on purpose with AllowInFileDuplicates
  * @param t *testing.T test harness
  * @return void
  */
@@ -295,3 +308,1546 @@ func TestUniqueWordsAllFiles(t *testing.T) {
 	}
 	t.Logf("Unique words across all files: %d", len(seen))
 }
+
+/**
+ * TestEnvVarNamesArePosix samples the EnvVar preset over the full corpus with a slug
+ * and checks every name against the posix name grammar with a leading letter and the length cap
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEnvVarNamesArePosix(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"**/*.txt"},
+		Strategy:     MergeByDir,
+		MinWords:     1,
+		MaxWords:     4,
+		SlugLength:   6,
+		Delimiter:    '-', // overridden by the preset
+		EnvVar:       true,
+		Seed:         99,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	posix := regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	for i := 0; i < 5000; i++ {
+		name := g.Generate(0)
+		if !posix.MatchString(name) {
+			t.Fatalf("not a posix env var name: %q", name)
+		}
+		if len(name) > envVarMaxLen {
+			t.Fatalf("name %q longer than %d", name, envVarMaxLen)
+		}
+	}
+
+	// a short cap still yields a valid name with no dangling delimiter
+	g.maxLen = 7
+	for i := 0; i < 500; i++ {
+		name := g.Generate(3)
+		if !posix.MatchString(name) || len(name) > 7 || strings.HasSuffix(name, "_") {
+			t.Fatalf("capped name invalid: %q", name)
+		}
+	}
+}
+
+/**
+ * TestEnvVarOverridesNameShapingOptions turns on every option that could break the
+ * posix grammar and checks the preset clears it or New refuses it
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEnvVarOverridesNameShapingOptions(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs:     []string{"**/*.txt"},
+		Strategy:         MergeByDir,
+		MinWords:         1,
+		MaxWords:         4,
+		SlugLength:       6,
+		SlugProbability:  0.5,
+		Delimiter:        ' ',
+		NoSlugDelimiter:  ' ',
+		WordDelimiters:   []byte("-."),
+		PrefixWithListID: true,
+		SlugGroup:        3,
+		SoftHyphenate:    3,
+		FixedWords:       map[int]string{1: "acme9"},
+		Slugs:            []SlugSpec{{Length: 2, Alphabet: "0123456789"}},
+		EnvVar:           true,
+		Seed:             99,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		name := g.Generate(0)
+		if name == "" || name[0] < 'A' || name[0] > 'Z' {
+			t.Fatalf("name %q does not start with a letter", name)
+		}
+		for j := 0; j < len(name); j++ {
+			if c := name[j]; !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+				t.Fatalf("name %q has byte %q outside [A-Z0-9_]", name, c)
+			}
+		}
+	}
+
+	bad := []Options{
+		{SlugOnly: true, SlugLength: 6},
+		{FixedWords: map[int]string{0: "9lives"}},
+		{Slugs: []SlugSpec{{Length: 3, Position: SlugPrefix}}},
+		{Slugs: []SlugSpec{{Length: 3, Alphabet: "ab-"}}},
+	}
+	for i, opts := range bad {
+		opts.IncludeGlobs = []string{"nouns/*.txt"}
+		opts.EnvVar = true
+		if _, err := New(opts); err == nil {
+			t.Fatalf("case %d: expected EnvVar to reject %+v", i, opts)
+		}
+	}
+}
+
+/**
+ * TestEnvVarCapKeepsCounterSuffix checks the length cap cuts the words and keeps
+ * the counter whole so long names under EnvVar still never repeat
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEnvVarCapKeepsCounterSuffix(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"**/*.txt"},
+		Strategy:     MergeByDir,
+		Words:        7,
+		CounterWidth: 4,
+		EnvVar:       true,
+		Seed:         5,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		name, err := g.GenerateE(0)
+		if err != nil {
+			t.Fatalf("GenerateE: %v", err)
+		}
+		if len(name) > envVarMaxLen || len(name) < 6 || name[len(name)-5] != '_' || name[len(name)-6] == '_' {
+			t.Fatalf("name %q lost its counter suffix under the %d byte cap", name, envVarMaxLen)
+		}
+		if seen[name] {
+			t.Fatalf("name %q repeated after %d calls", name, i)
+		}
+		seen[name] = true
+	}
+
+	if _, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, CounterWidth: 4, MaxTotalLen: 5}); err == nil {
+		t.Fatal("expected an error for a cap with no room before the counter")
+	}
+}
+
+/**
+ * TestUpperWithAndWithoutSlug covers ascii and rune aware uppercasing plus the lowercase slug sub flag
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestUpperWithAndWithoutSlug(t *testing.T) {
+	g := &Generator{
+		lists:      [][]string{{"brave"}, {"otter"}},
+		delim:      []byte("_"),
+		wordsExact: 2,
+		upper:      true,
+		rng:        rand.New(rand.NewSource(1)),
+	}
+	if got := g.Generate(0); got != "BRAVE_OTTER" {
+		t.Fatalf("upper no slug got %q", got)
+	}
+
+	// slug follows the name casing by default
+	g.slugLen = 8
+	g.upperSlug = true
+	name := g.Generate(0)
+	if !strings.HasPrefix(name, "BRAVE_OTTER_") || strings.ToUpper(name) != name {
+		t.Fatalf("upper with slug got %q", name)
+	}
+
+	// sub flag leaves the slug lowercase
+	g.upperSlug = false
+	name = g.Generate(0)
+	slug := name[len("BRAVE_OTTER_"):]
+	if strings.ToLower(slug) != slug || len(slug) != 8 {
+		t.Fatalf("lower slug got %q", name)
+	}
+
+	// ascii mode leaves non ascii letters alone rune mode uppercases them
+	g.slugLen = 0
+	g.lists = [][]string{{"éclair"}}
+	g.wordsExact = 1
+	if got := g.Generate(0); got != "éCLAIR" {
+		t.Fatalf("ascii upper got %q", got)
+	}
+	g.upperRunes = true
+	if got := g.Generate(0); got != "ÉCLAIR" {
+		t.Fatalf("rune upper got %q", got)
+	}
+
+	// zero alloc path still holds with upper on
+	g.upperRunes = false
+	g.lists = [][]string{{"brave"}, {"otter"}}
+	g.wordsExact = 2
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(100, func() { buf = g.GenerateInto(buf[:0], 0) }); n != 0 && !raceEnabled {
+		t.Fatalf("GenerateInto with upper allocated %v times", n)
+	}
+}
+
+/**
+ * TestGenerateRangePerCall asserts per call ranges override the exact setting and stay in bounds
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateRangePerCall(t *testing.T) {
+	g := newTestGen() // configured for exactly two words
+	seen := map[int]int{}
+	for i := 0; i < 500; i++ {
+		n := strings.Count(g.GenerateRange(2, 4), "_") + 1
+		if n < 2 || n > 4 {
+			t.Fatalf("GenerateRange(2,4) produced %d words", n)
+		}
+		seen[n]++
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected all of 2..4 to appear got %v", seen)
+	}
+
+	// inverted bounds collapse to min and the next default call is unaffected
+	if n := strings.Count(g.GenerateRange(3, 1), "_") + 1; n != 3 {
+		t.Fatalf("GenerateRange(3,1) produced %d words", n)
+	}
+	if n := strings.Count(g.Generate(0), "_") + 1; n != 2 {
+		t.Fatalf("default after range produced %d words", n)
+	}
+}
+
+/**
+ * TestWriteToMatchesGenerate checks two generators with the same seed agree across both apis
+ * and that WriteTo does not allocate once the pool is warm
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestWriteToMatchesGenerate(t *testing.T) {
+	a, b := newTestGen(), newTestGen()
+	a.lists = [][]string{{"alpha", "beta", "gamma"}, {"one", "two", "three"}}
+	b.lists = a.lists
+
+	var buf bytes.Buffer
+	for i := 0; i < 50; i++ {
+		buf.Reset()
+		if _, err := a.WriteTo(&buf, 0); err != nil {
+			t.Fatalf("WriteTo: %v", err)
+		}
+		if want := b.Generate(0); buf.String() != want {
+			t.Fatalf("WriteTo wrote %q Generate gave %q", buf.String(), want)
+		}
+	}
+
+	if n := testing.AllocsPerRun(100, func() { _, _ = a.WriteTo(io.Discard, 0) }); n != 0 && !raceEnabled {
+		t.Fatalf("WriteTo allocated %v times per call", n)
+	}
+}
+
+/**
+ * TestGenerateDetailedReportsWordCount compares reported counts with the delimiters in each name
+ * across a randomized range
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateDetailedReportsWordCount(t *testing.T) {
+	g := newTestGen()
+	g.wordsExact = 0
+	g.minWords, g.maxWords = 1, 4
+
+	hist := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		d := g.GenerateDetailed(0)
+		if got := strings.Count(d.Name, "_") + 1; got != d.Words {
+			t.Fatalf("reported %d words but %q has %d", d.Words, d.Name, got)
+		}
+		hist[d.Words]++
+	}
+	for n := 1; n <= 4; n++ {
+		if hist[n] == 0 {
+			t.Fatalf("count %d never reported %v", n, hist)
+		}
+	}
+
+	if d := g.GenerateDetailed(3); d.Words != 3 {
+		t.Fatalf("override reported %d", d.Words)
+	}
+}
+
+/**
+ * TestConcurrentGenerateLockedDefault hammers a default generator from many goroutines
+ * run with -race to confirm the locked path stays safe
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestConcurrentGenerateLockedDefault(t *testing.T) {
+	g := newTestGen()
+	g.minWords, g.maxWords, g.wordsExact = 1, 3, 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 0, 32)
+			for i := 0; i < 500; i++ {
+				buf = g.GenerateInto(buf[:0], 0)
+				_ = g.Generate(0)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+/**
+ * TestSingleThreadedMatchesLocked checks the lock free path produces the same stream
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSingleThreadedMatchesLocked(t *testing.T) {
+	a, b := newTestGen(), newTestGen()
+	b.singleThreaded = true
+	for i := 0; i < 100; i++ {
+		if x, y := a.Generate(0), b.Generate(0); x != y {
+			t.Fatalf("single threaded diverged at %d: %q vs %q", i, x, y)
+		}
+	}
+}
+
+/**
+ * TestPositionCountsGenerations checks Position grows by one per generated name
+ * across the string byte and range apis
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPositionCountsGenerations(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Seed: 5})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if g.Position() != 0 {
+		t.Fatalf("fresh generator at position %d", g.Position())
+	}
+	buf := make([]byte, 0, 64)
+	for i := 0; i < 10; i++ {
+		g.Generate(0)
+		buf = g.GenerateInto(buf[:0], 3)
+		g.GenerateRange(1, 4)
+	}
+	if g.Position() != 30 {
+		t.Fatalf("expected position 30 got %d", g.Position())
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				g.Generate(0)
+			}
+		}()
+	}
+	wg.Wait()
+	if g.Position() != 230 {
+		t.Fatalf("expected position 230 got %d", g.Position())
+	}
+}
+
+/**
+ * TestSkipMatchesGenerating checks Skip(n) then Generate equals the n+1th name of a twin
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSkipMatchesGenerating(t *testing.T) {
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, MinWords: 1, MaxWords: 4, CounterWidth: 3, Seed: 8}
+	a, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	const n = 37
+	for i := 0; i < n; i++ {
+		a.Generate(0)
+	}
+	b.Skip(n)
+	if b.Position() != n {
+		t.Fatalf("expected position %d after Skip got %d", n, b.Position())
+	}
+	for i := 0; i < 5; i++ {
+		if x, y := a.Generate(0), b.Generate(0); x != y {
+			t.Fatalf("name %d after skip differs: %q vs %q", i, x, y)
+		}
+	}
+}
+
+/**
+ * TestSkipRedrawsLikeGenerate checks Skip spends the same redraws as Generate
+ * when ReservedWords rejects some candidates so the streams stay aligned
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSkipRedrawsLikeGenerate(t *testing.T) {
+	files := map[string][]string{"a": {"admin", "root", "otter", "heron"}}
+	opts := Options{
+		ReservedWords: []string{"admin", "root"},
+		Words:         1,
+		Seed:          8,
+	}
+	a, _ := NewFromFiles(files, opts)
+	b, err := NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	const n = 40
+	for i := 0; i < n; i++ {
+		a.Generate(0)
+	}
+	b.Skip(n)
+	if a.Position() <= n || b.Position() != a.Position() {
+		t.Fatalf("positions %d and %d want equal and past %d", a.Position(), b.Position(), n)
+	}
+	for i := 0; i < 20; i++ {
+		if x, y := a.Generate(0), b.Generate(0); x != y {
+			t.Fatalf("name %d after skip differs: %q vs %q", i, x, y)
+		}
+	}
+
+	// a logged Position counts rejected draws so replay skips the accepted count
+	a, _ = NewFromFiles(files, opts)
+	var names []string
+	var logged []uint64
+	for i := 0; i < n; i++ {
+		logged = append(logged, a.Position())
+		names = append(names, a.Generate(0))
+	}
+	k := n - 1
+	if logged[k] <= uint64(k) {
+		t.Fatalf("position %d before name %d shows no redraws", logged[k], k)
+	}
+	c, _ := NewFromFiles(files, opts)
+	c.Skip(k)
+	if c.Position() != logged[k] {
+		t.Fatalf("Skip(%d) reached position %d want %d", k, c.Position(), logged[k])
+	}
+	if got := c.Generate(0); got != names[k] {
+		t.Fatalf("replayed name %d = %q want %q", k, got, names[k])
+	}
+}
+
+/**
+ * TestLengthBiasShiftsAverageLength checks a negative bias draws shorter words than uniform
+ * and a positive bias longer ones over many samples
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestLengthBiasShiftsAverageLength(t *testing.T) {
+	avg := func(bias float64) float64 {
+		g, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, Strategy: MergeSingle, Words: 1, LengthBias: bias, Seed: 17})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		total := 0
+		const n = 5000
+		for i := 0; i < n; i++ {
+			total += len(g.Generate(0))
+		}
+		return float64(total) / n
+	}
+	short, uniform, long := avg(-3), avg(0), avg(3)
+	if !(short < uniform-0.5 && long > uniform+0.5) {
+		t.Fatalf("bias did not shift lengths: short %.2f uniform %.2f long %.2f", short, uniform, long)
+	}
+}
+
+/**
+ * TestWordDelimitersCycle checks each boundary uses the configured delimiter in turn
+ * while the slug keeps Delimiter and Parse splits the words back
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestWordDelimitersCycle(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave"}, "b/y.txt": {"otter"}, "c/z.txt": {"swift"}}
+	opts := Options{Strategy: MergeByFile, Words: 4, WordDelimiters: []byte("-."), SlugLength: 4, Seed: 1}
+	g, err := NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	name := g.Generate(0)
+	if !strings.HasPrefix(name, "brave-otter.swift-brave_") || len(name) != len("brave-otter.swift-brave_")+4 {
+		t.Fatalf("unexpected boundaries in %q", name)
+	}
+	words, slug, ok := Parse(name, opts)
+	if !ok || len(words) != 4 || words[2] != "swift" || len(slug) != 4 {
+		t.Fatalf("Parse(%q) = %v %q %v", name, words, slug, ok)
+	}
+}
+
+/**
+ * TestAllowedWordCountsOnlyUsesSet checks only the configured counts appear
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestAllowedWordCountsOnlyUsesSet(t *testing.T) {
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, AllowedWordCounts: []int{2, 4}, Seed: 6}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	seen := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		seen[strings.Count(g.Generate(0), "_")+1]++
+	}
+	if len(seen) != 2 || seen[2] == 0 || seen[4] == 0 {
+		t.Fatalf("unexpected counts %v", seen)
+	}
+	if _, err := New(Options{AllowedWordCounts: []int{2, 0}}); err == nil {
+		t.Fatal("expected error for a zero count")
+	}
+}
+
+/**
+ * TestSuffixStrategyChoosesSuffix checks each strategy emits the expected suffix kind
+ * slugs are 6 chars and counters 3 so the suffix length tells them apart
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSuffixStrategyChoosesSuffix(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave"}}
+	kinds := func(s SuffixStrategy) []string {
+		g, err := NewFromFiles(files, Options{Words: 1, SlugLength: 6, CounterWidth: 3, SuffixStrategy: s, Seed: 9})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		var out []string
+		for i := 0; i < 40; i++ {
+			parts := strings.Split(g.Generate(0), "_")
+			switch {
+			case len(parts) == 3 && len(parts[1]) == 6 && len(parts[2]) == 3:
+				out = append(out, "both")
+			case len(parts) == 2 && len(parts[1]) == 6:
+				out = append(out, "slug")
+			case len(parts) == 2 && len(parts[1]) == 3:
+				out = append(out, "counter")
+			default:
+				t.Fatalf("unexpected shape %v", parts)
+			}
+		}
+		return out
+	}
+	only := func(got []string, want string) bool {
+		for _, k := range got {
+			if k != want {
+				return false
+			}
+		}
+		return true
+	}
+
+	if got := kinds(SuffixBoth); !only(got, "both") {
+		t.Fatalf("both: %v", got)
+	}
+	if got := kinds(SuffixSlug); !only(got, "slug") {
+		t.Fatalf("slug: %v", got)
+	}
+	if got := kinds(SuffixCounter); !only(got, "counter") {
+		t.Fatalf("counter: %v", got)
+	}
+	alt := kinds(SuffixAlternate)
+	for i, k := range alt {
+		if want := map[bool]string{true: "counter", false: "slug"}[i%2 == 0]; k != want {
+			t.Fatalf("alternate %d: got %s", i, k)
+		}
+	}
+	rnd := kinds(SuffixRandom)
+	if only(rnd, "slug") || only(rnd, "counter") {
+		t.Fatalf("random never mixed: %v", rnd)
+	}
+}
+
+/**
+ * TestMaxGenerationsQuota checks the first N calls succeed and the next one errors
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMaxGenerationsQuota(t *testing.T) {
+	g, err := NewFromFiles(map[string][]string{"a/x.txt": {"brave", "calm"}}, Options{MaxGenerations: 5, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if name, err := g.GenerateE(0); err != nil || name == "" {
+			t.Fatalf("call %d: %q %v", i, name, err)
+		}
+	}
+	if name, err := g.GenerateE(0); !errors.Is(err, ErrQuotaExceeded) || name != "" {
+		t.Fatalf("expected ErrQuotaExceeded got %q %v", name, err)
+	}
+	if g.GenerateRange(1, 2) != "" || g.Generate(0) != "" {
+		t.Fatal("expected empty names once the quota is spent")
+	}
+}
+
+/**
+ * TestWordFromNamedList checks Word draws members of the named list and rejects unknown ids
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestWordFromNamedList(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Seed: 2})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	members := make(map[string]bool)
+	for _, w := range g.lists[0] {
+		members[w] = true
+	}
+	for i := 0; i < 50; i++ {
+		w, err := g.Word("adjectives")
+		if err != nil || !members[w] {
+			t.Fatalf("Word = %q %v", w, err)
+		}
+	}
+	if _, err := g.Word("ghosts"); err == nil {
+		t.Fatal("expected error for unknown list id")
+	}
+}
+
+/**
+ * TestContainsRespectsFilters checks known words are members and filtered words are not
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestContainsRespectsFilters(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"Brave", "calm", "enormous"}, "b/y.txt": {"otter"}}
+	g, err := NewFromFiles(files, Options{Lowercase: true, MaxLen: 6, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, w := range []string{"brave", "Brave", "otter", "calm"} {
+		if !g.Contains(w) {
+			t.Fatalf("expected %q to be a member", w)
+		}
+	}
+	for _, w := range []string{"enormous", "heron", ""} {
+		if g.Contains(w) {
+			t.Fatalf("expected %q to be filtered out", w)
+		}
+	}
+}
+
+/**
+ * TestCryptoWordsStaysInRange checks crypto drawn indices stay in range and reach every word
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCryptoWordsStaysInRange(t *testing.T) {
+	var ent entropyBuf
+	for _, n := range []int{1, 3, 7, 1000} {
+		for i := 0; i < 2000; i++ {
+			if v := ent.intn(n); v < 0 || v >= n {
+				t.Fatalf("intn(%d) = %d", n, v)
+			}
+		}
+	}
+
+	files := map[string][]string{"a/x.txt": {"brave", "calm", "eager"}, "b/y.txt": {"otter", "heron"}}
+	g, err := NewFromFiles(files, Options{Strategy: MergeByFile, CryptoWords: true, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		parts := strings.Split(g.Generate(0), "_")
+		if len(parts) != 2 || !g.Contains(parts[0]) || !g.Contains(parts[1]) {
+			t.Fatalf("bad name parts %v", parts)
+		}
+		seen[parts[0]], seen[parts[1]] = true, true
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected every word to appear got %v", seen)
+	}
+}
+
+/**
+ * TestPrefixWithListIDTagsWords checks each word carries the id of the list it came from
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPrefixWithListIDTagsWords(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 3, SlugLength: 4, PrefixWithListID: true, Seed: 12})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	want := []string{"adjectives", "nouns", "adjectives"}
+	buf := make([]byte, 0, 128)
+	for i := 0; i < 50; i++ {
+		buf = g.GenerateInto(buf[:0], 0)
+		parts := strings.Split(string(buf), "_")
+		if len(parts) != 4 || len(parts[3]) != 4 {
+			t.Fatalf("unexpected shape %q", buf)
+		}
+		for j, p := range parts[:3] {
+			id, w, ok := strings.Cut(p, ":")
+			if !ok || id != want[j] || !g.Contains(w) {
+				t.Fatalf("word %d of %q not tagged with %s", j, buf, want[j])
+			}
+		}
+		if cap(buf) != 128 {
+			t.Fatal("buffer had to grow so sizing missed the id bytes")
+		}
+	}
+}
+
+/**
+ * TestNoSlugDelimiterFollowsSlugPresence checks slugless names use the bare delimiter
+ * and slugged names keep Delimiter over many samples
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNoSlugDelimiterFollowsSlugPresence(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave", "calm"}, "b/y.txt": {"otter", "heron"}}
+	opts := Options{Strategy: MergeByFile, Delimiter: '-', SlugLength: 5, SlugProbability: 0.5, NoSlugDelimiter: ' ', Seed: 14}
+	g, err := NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	slugged, bare := 0, 0
+	for i := 0; i < 400; i++ {
+		name := g.Generate(0)
+		if words, slug, ok := Parse(name, opts); !ok || len(words) != 2 || strings.ContainsAny(words[1], " -") || slug != "" && !strings.HasSuffix(name, "-"+slug) {
+			t.Fatalf("Parse(%q) = %q %q %v", name, words, slug, ok)
+		}
+		switch {
+		case strings.Count(name, "-") == 2 && !strings.Contains(name, " "):
+			slugged++
+		case strings.Count(name, " ") == 1 && !strings.Contains(name, "-"):
+			bare++
+		default:
+			t.Fatalf("delimiter does not match slug presence in %q", name)
+		}
+	}
+	if slugged < 100 || bare < 100 {
+		t.Fatalf("expected a mix got slugged %d bare %d", slugged, bare)
+	}
+	if _, err := NewFromFiles(files, Options{SlugProbability: 1.5}); err == nil {
+		t.Fatal("expected error for SlugProbability above one")
+	}
+
+	// NameAt joins like the slugless build here but like the slugged one when every name has a slug
+	if at, _ := g.NameAt(big.NewInt(0), 2); at != "brave otter" {
+		t.Fatalf("NameAt with optional slug = %q want brave otter", at)
+	}
+	opts.SlugProbability = 0
+	g, _ = NewFromFiles(files, opts)
+	at, _ := g.NameAt(big.NewInt(0), 2)
+	if name := g.Generate(0); at != "brave-otter" || strings.Contains(name, " ") {
+		t.Fatalf("NameAt = %q next to Generate %q want brave-otter", at, name)
+	}
+}
+
+/**
+ * TestAvoidCommonPrefixBetweenConsecutiveNames checks neighbours differ in the first N chars
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestAvoidCommonPrefixBetweenConsecutiveNames(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave", "bright", "calm", "clever", "eager"}, "b/y.txt": {"otter", "heron"}}
+	const n = 2
+	g, err := NewFromFiles(files, Options{Strategy: MergeByFile, AvoidCommonPrefix: n, Seed: 8})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	prev := g.Generate(0)
+	for i := 0; i < 300; i++ {
+		name := g.Generate(0)
+		if name[:n] == prev[:n] {
+			t.Fatalf("%q follows %q with the same %d char prefix", name, prev, n)
+		}
+		prev = name
+	}
+}
+
+/**
+ * TestGenerateExcludingCountsSkipsValues checks excluded word counts never appear
+ * and that excluding every configured count is an error
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateExcludingCountsSkipsValues(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, MinWords: 1, MaxWords: 4, Delimiter: '-', Seed: 8})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	seen := map[int]int{}
+	for i := 0; i < 400; i++ {
+		name, err := g.GenerateExcludingCounts([]int{3})
+		if err != nil {
+			t.Fatalf("GenerateExcludingCounts: %v", err)
+		}
+		seen[len(strings.Split(name, "-"))]++
+	}
+	if seen[3] > 0 {
+		t.Fatalf("excluded count 3 appeared %d times", seen[3])
+	}
+	for _, n := range []int{1, 2, 4} {
+		if seen[n] == 0 {
+			t.Fatalf("count %d never drawn: %v", n, seen)
+		}
+	}
+	if _, err := g.GenerateExcludingCounts([]int{1, 2, 3, 4}); err == nil {
+		t.Fatal("excluding every count should fail")
+	}
+}
+
+/**
+ * TestMinDistanceBetweenConsecutiveNames checks every name is at least MinDistance
+ * edits from the one before it and spot checks the distance helper
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMinDistanceBetweenConsecutiveNames(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{{"kitten", "sitting", 3}, {"", "abc", 3}, {"otter", "otter", 0}, {"café", "cafe", 1}} {
+		if got := levenshtein([]byte(c.a), []byte(c.b)); got != c.want {
+			t.Fatalf("levenshtein(%q, %q) = %d want %d", c.a, c.b, got, c.want)
+		}
+	}
+
+	files := map[string][]string{"a.txt": {"cat", "bat", "cab", "dog", "cot"}, "b.txt": {"run", "ran", "rug", "sky"}}
+	g, err := NewFromFiles(files, Options{Words: 2, MinDistance: 3, Seed: 6})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	prev := g.Generate(0)
+	for i := 0; i < 200; i++ {
+		name := g.Generate(0)
+		if d := levenshtein([]byte(prev), []byte(name)); d < 3 {
+			t.Fatalf("%q follows %q at distance %d", name, prev, d)
+		}
+		prev = name
+	}
+
+	if _, err := NewFromFiles(files, Options{MinDistance: -1, Seed: 6}); err == nil {
+		t.Fatal("negative MinDistance should fail")
+	}
+}
+
+/**
+ * TestSetWordRangeAtRuntime switches the name shape mid run while other goroutines
+ * generate and checks later names follow the new bounds
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSetWordRangeAtRuntime(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 2, Delimiter: '-', Seed: 17})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	words := func() int { return g.GenerateDetailed(0).Words }
+	if n := words(); n != 2 {
+		t.Fatalf("start with %d words want 2", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				g.Generate(0)
+			}
+		}()
+	}
+	if err := g.SetWordRange(3, 5); err != nil {
+		t.Fatalf("SetWordRange: %v", err)
+	}
+	wg.Wait()
+	for i := 0; i < 100; i++ {
+		if n := words(); n < 3 || n > 5 {
+			t.Fatalf("after SetWordRange(3, 5) got %d words", n)
+		}
+	}
+
+	if err := g.SetWords(4); err != nil {
+		t.Fatalf("SetWords: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if n := words(); n != 4 {
+			t.Fatalf("after SetWords(4) got %d words", n)
+		}
+	}
+	if err := g.SetWordRange(4, 2); err == nil {
+		t.Fatal("max below min should fail")
+	}
+	if err := g.SetWords(-1); err == nil {
+		t.Fatal("negative Words should fail")
+	}
+	if _, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, MinWords: 3, MaxWords: 1, Seed: 1}); err == nil {
+		t.Fatal("New should apply the same rule")
+	}
+}
+
+/**
+ * TestPositionSamplingModes weights position zero by length while position one
+ * keeps equal odds over a list of the same shape
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPositionSamplingModes(t *testing.T) {
+	files := map[string][]string{"a.txt": {"ox", "elephant"}, "b.txt": {"ox", "elephant"}}
+	g, err := NewFromFiles(files, Options{
+		Words:            2,
+		LengthBias:       4,
+		PositionSampling: []PositionSampling{SamplingWeighted, SamplingUniform},
+		Seed:             23,
+	})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	long := [2]int{}
+	const n = 4000
+	for i := 0; i < n; i++ {
+		parts := strings.Split(g.Generate(0), "_")
+		for p, w := range parts {
+			if w == "elephant" {
+				long[p]++
+			}
+		}
+	}
+	// 8^4 against 2^4 gives the long word 256 of 257 odds when weighted
+	if float64(long[0])/n < 0.98 {
+		t.Fatalf("weighted position picked elephant %d of %d", long[0], n)
+	}
+	if frac := float64(long[1]) / n; frac < 0.45 || frac > 0.55 {
+		t.Fatalf("uniform position picked elephant %d of %d", long[1], n)
+	}
+
+	if _, err := NewFromFiles(files, Options{PositionSampling: []PositionSampling{SamplingWeighted}, Seed: 1}); err == nil {
+		t.Fatal("SamplingWeighted without LengthBias should fail")
+	}
+}
+
+/**
+ * TestSoftHyphenateLongWords checks long words are broken every N runes without
+ * losing letters short words stay whole and build sizes its buffer for the hyphens
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSoftHyphenateLongWords(t *testing.T) {
+	for _, c := range []struct {
+		in, want string
+		n        int
+	}{
+		{"incomprehensible", "incompre-hensible", 8},
+		{"abcdefghij", "abc-def-ghi-j", 3},
+		{"abcdef", "abc-def", 3},
+		{"short", "short", 8},
+		{"éclairées", "écl-air-ées", 3},
+	} {
+		got := softHyphenate([]byte("x_"+c.in), 2, c.n)
+		if string(got) != "x_"+c.want {
+			t.Fatalf("softHyphenate(%q, %d) = %q want %q", c.in, c.n, got[2:], c.want)
+		}
+		if extra := len(c.want) - len(c.in); hyphens(c.in, c.n) != extra {
+			t.Fatalf("hyphens(%q, %d) = %d want %d", c.in, c.n, hyphens(c.in, c.n), extra)
+		}
+	}
+
+	files := map[string][]string{"a.txt": {"incomprehensible", "otter"}}
+	g, err := NewFromFiles(files, Options{Words: 1, SoftHyphenate: 8, Seed: 4})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if name := g.Generate(0); name != "incompre-hensible" && name != "otter" {
+			t.Fatalf("got %q", name)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if out := g.GenerateInto(nil, 0); cap(out) != len(out) {
+			t.Fatalf("%q sized to %d bytes", out, cap(out))
+		}
+	}
+}
+
+/**
+ * TestNoDelimiterConcatenates checks NoDelimiter joins words with no separator
+ * while the slug keeps Delimiter and the buffer is sized without boundary bytes
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNoDelimiterConcatenates(t *testing.T) {
+	files := map[string][]string{"a.txt": {"brave"}, "b.txt": {"otter"}}
+	g, err := NewFromFiles(files, Options{Words: 2, NoDelimiter: true, WordDelimiters: []byte("-"), NoSlugDelimiter: ' ', Seed: 1})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	if name := g.Generate(0); name != "braveotter" {
+		t.Fatalf("got %q want braveotter", name)
+	}
+	if out := g.GenerateInto(nil, 0); cap(out) != len(out) {
+		t.Fatalf("%q sized to %d bytes", out, cap(out))
+	}
+	if name, err := g.NameAt(big.NewInt(0), 2); err != nil || name != "braveotter" {
+		t.Fatalf("NameAt = %q, %v", name, err)
+	}
+
+	g, err = NewFromFiles(files, Options{Words: 2, NoDelimiter: true, SlugLength: 6, Seed: 1})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	name := g.Generate(0)
+	if !strings.HasPrefix(name, "braveotter_") || len(name) != len("braveotter_")+6 {
+		t.Fatalf("got %q", name)
+	}
+	if out := g.GenerateInto(nil, 0); cap(out) != len(out) {
+		t.Fatalf("%q sized to %d bytes", out, cap(out))
+	}
+	words, slug, ok := Parse(name, Options{Words: 2, NoDelimiter: true, SlugLength: 6})
+	if !ok || len(words) != 1 || words[0] != "braveotter" || slug != name[len(name)-6:] {
+		t.Fatalf("Parse(%q) = %q, %q, %v", name, words, slug, ok)
+	}
+}
+
+/**
+ * TestRecencyCooldownEvensCoverage checks RecencyCooldown spreads a long stream
+ * more evenly over the vocabulary than plain uniform picks by comparing the
+ * chi square of word counts summed over several seeds
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestRecencyCooldownEvensCoverage(t *testing.T) {
+	words := make([]string, 50)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%02d", i)
+	}
+	files := map[string][]string{"a.txt": words}
+	chi := func(cooldown int) float64 {
+		total := 0.0
+		for seed := int64(1); seed <= 5; seed++ {
+			g, err := NewFromFiles(files, Options{Words: 1, RecencyCooldown: cooldown, Seed: seed})
+			if err != nil {
+				t.Fatalf("NewFromFiles: %v", err)
+			}
+			const draws = 5000
+			counts := make(map[string]int, len(words))
+			for i := 0; i < draws; i++ {
+				counts[g.Generate(0)]++
+			}
+			want := float64(draws) / float64(len(words))
+			for _, w := range words {
+				d := float64(counts[w]) - want
+				total += d * d / want
+			}
+		}
+		return total
+	}
+	plain, cooled := chi(0), chi(25)
+	if cooled >= plain*3/4 {
+		t.Fatalf("chi square with cooldown %.1f not well below plain %.1f", cooled, plain)
+	}
+
+	if _, err := NewFromFiles(files, Options{RecencyCooldown: -1}); err == nil {
+		t.Fatalf("expected error for negative RecencyCooldown")
+	}
+	g, err := NewFromFiles(map[string][]string{"a.txt": {"solo"}}, Options{Words: 1, RecencyCooldown: 10})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if name := g.Generate(0); name != "solo" {
+			t.Fatalf("got %q", name)
+		}
+	}
+}
+
+/**
+ * TestFixedWordsInterleave checks a FixedWords literal always sits at its
+ * position while the sampled words around it keep their lists and buffer sizing
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestFixedWordsInterleave(t *testing.T) {
+	files := map[string][]string{
+		"adjectives/a.txt": {"brave", "happy", "eager"},
+		"nouns/n.txt":      {"otter", "fox", "newt"},
+	}
+	g, err := NewFromFiles(files, Options{Words: 2, Delimiter: '-', FixedWords: map[int]string{1: "acme"}, Seed: 3})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		out := g.GenerateInto(nil, 0)
+		if cap(out) != len(out) {
+			t.Fatalf("%q sized to %d bytes", out, cap(out))
+		}
+		parts := strings.Split(string(out), "-")
+		if len(parts) != 3 || parts[1] != "acme" || !slices.Contains(files["adjectives/a.txt"], parts[0]) || !slices.Contains(files["nouns/n.txt"], parts[2]) {
+			t.Fatalf("got %q", out)
+		}
+		seen[parts[0]+parts[2]] = true
+	}
+	if len(seen) < 5 {
+		t.Fatalf("only %d word pairs sampled around the literal", len(seen))
+	}
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(50, func() { buf = g.GenerateInto(buf[:0], 0) }); n != 0 && !raceEnabled {
+		t.Fatalf("GenerateInto with FixedWords allocated %v times", n)
+	}
+	if name, err := g.NameAt(big.NewInt(0), 2); err != nil || name != "brave-acme-otter" {
+		t.Fatalf("NameAt = %q, %v", name, err)
+	}
+
+	// a literal past the end of the name is left out and one at the end follows it
+	g, err = NewFromFiles(files, Options{Words: 1, FixedWords: map[int]string{1: "co", 5: "x"}, PrefixWithListID: true, Seed: 3})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	if name := g.Generate(0); !strings.HasPrefix(name, "adjectives/a.txt:") || !strings.HasSuffix(name, "_co") {
+		t.Fatalf("got %q", name)
+	}
+
+	if _, err := NewFromFiles(files, Options{FixedWords: map[int]string{-1: "x"}}); err == nil {
+		t.Fatalf("expected error for negative position")
+	}
+	if _, err := NewFromFiles(files, Options{FixedWords: map[int]string{0: ""}}); err == nil {
+		t.Fatalf("expected error for empty literal")
+	}
+}
+
+/**
+ * TestWordCaseTransforms checks each WordCase recases just the words in place
+ * leaving the slug lowercase and GenerateInto free of allocations
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestWordCaseTransforms(t *testing.T) {
+	files := map[string][]string{"a.txt": {"brave"}, "b.txt": {"oTTer"}}
+	for _, c := range []struct {
+		mode WordCase
+		want string
+	}{
+		{CaseNone, "brave_oTTer_"},
+		{CaseTitle, "Brave_OTTer_"},
+		{CaseUpper, "BRAVE_OTTER_"},
+		{CaseLower, "brave_otter_"},
+	} {
+		g, err := NewFromFiles(files, Options{Words: 2, SlugLength: 6, WordCase: c.mode, Seed: 1})
+		if err != nil {
+			t.Fatalf("NewFromFiles: %v", err)
+		}
+		name := g.Generate(0)
+		if !strings.HasPrefix(name, c.want) {
+			t.Fatalf("WordCase %d gave %q want prefix %q", c.mode, name, c.want)
+		}
+		if slug := name[len(c.want):]; slug != strings.ToLower(slug) {
+			t.Fatalf("WordCase %d recased the slug %q", c.mode, slug)
+		}
+		buf := make([]byte, 0, 64)
+		if n := testing.AllocsPerRun(50, func() { buf = g.GenerateInto(buf[:0], 0) }); n != 0 && !raceEnabled {
+			t.Fatalf("WordCase %d GenerateInto allocated %v times", c.mode, n)
+		}
+	}
+	if _, err := NewFromFiles(files, Options{WordCase: CaseLower + 1}); err == nil {
+		t.Fatalf("expected error for unknown WordCase")
+	}
+}
+
+/**
+ * TestListsReportsIDs checks Lists returns each list id with its word count
+ * in the order New built them
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestListsReportsIDs(t *testing.T) {
+	files := map[string][]string{
+		"adjectives/a.txt": {"brave", "happy"},
+		"adjectives/b.txt": {"eager"},
+		"nouns/n.txt":      {"otter", "fox", "newt", "owl"},
+	}
+	g, err := NewFromFiles(files, Options{Strategy: MergeByDir})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	want := []ListInfo{{ID: "adjectives", Words: 3}, {ID: "nouns", Words: 4}}
+	if got := g.Lists(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lists = %+v want %+v", got, want)
+	}
+	if got := (&Generator{}).Lists(); len(got) != 0 {
+		t.Fatalf("empty generator Lists = %+v", got)
+	}
+}
+
+/**
+ * TestGenerateNBatch checks GenerateN returns n names matching the naive loop
+ * from the same seed and an empty slice for n of zero
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateNBatch(t *testing.T) {
+	a, err := New(Options{MinWords: 1, MaxWords: 3, Delimiter: '-', Seed: 8})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b, err := New(Options{MinWords: 1, MaxWords: 3, Delimiter: '-', Seed: 8})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	names := a.GenerateN(500, 0)
+	if len(names) != 500 {
+		t.Fatalf("GenerateN gave %d names", len(names))
+	}
+	for i, name := range names {
+		if want := b.Generate(0); name != want {
+			t.Fatalf("name %d = %q want %q", i, name, want)
+		}
+	}
+	if got := a.GenerateN(0, 0); got == nil || len(got) != 0 {
+		t.Fatalf("GenerateN(0) = %#v", got)
+	}
+	if got := (&Generator{}).GenerateN(2, 0); len(got) != 2 || got[0] != "" || got[1] != "" {
+		t.Fatalf("empty generator GenerateN = %q", got)
+	}
+}
+
+/**
+ * TestStreamCancels checks Stream emits names until the context is cancelled
+ * and then closes its channel and that a failing draw closes it too
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestStreamCancels(t *testing.T) {
+	g, err := New(Options{Seed: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := g.Stream(ctx, 2)
+	for i := 0; i < 25; i++ {
+		if name := <-ch; strings.Count(name, "_") != 1 {
+			t.Fatalf("received %q", name)
+		}
+	}
+	cancel()
+
+	// drain whatever was in flight and wait for the close
+	closed := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("channel still open after cancel")
+	}
+
+	g, err = New(Options{MaxGenerations: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	n := 0
+	for range g.Stream(context.Background(), 0) {
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("quota stream sent %d names want 3", n)
+	}
+}
+
+/**
+ * TestSourceOverridesSeed checks Options.Source drives generation instead of
+ * Seed that a shared source continues across constructions and that a nil
+ * source keeps the seeded rng
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSourceOverridesSeed(t *testing.T) {
+	draw := func(opts Options) []string {
+		g, err := New(opts)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return g.GenerateN(20, 0)
+	}
+
+	// Source wins over Seed so generators on equal sources agree whatever the seed
+	a := draw(Options{Seed: 1, Source: rand.NewSource(77)})
+	if b := draw(Options{Seed: 2, Source: rand.NewSource(77)}); !reflect.DeepEqual(a, b) {
+		t.Fatalf("Seed changed names under one Source:\n%v\n%v", a, b)
+	}
+	if c := draw(Options{Seed: 77}); !reflect.DeepEqual(a, c) {
+		t.Fatalf("Source(77) should match Seed 77 through rand.New:\n%v\n%v", a, c)
+	}
+
+	// one shared source keeps advancing across constructions
+	shared := rand.NewSource(5)
+	first, second := draw(Options{Source: shared}), draw(Options{Source: shared})
+	if reflect.DeepEqual(first, second) {
+		t.Fatalf("shared source restarted for the second generator")
+	}
+	r := rand.NewSource(5)
+	if replay := append(draw(Options{Source: r}), draw(Options{Source: r})...); !reflect.DeepEqual(replay, append(first, second...)) {
+		t.Fatalf("shared source stream did not replay")
+	}
+
+	// nil falls back to the seeded rng
+	if d, e := draw(Options{Seed: 9, Source: nil}), draw(Options{Seed: 9}); !reflect.DeepEqual(d, e) {
+		t.Fatalf("nil Source changed seeded names")
+	}
+}
+
+/**
+ * TestShardedDrawsUnderContention runs many generating goroutines against
+ * SetWordRange so contended calls take shards and still honour the shape
+ * and checks a lone goroutine keeps the seeded sequence
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestShardedDrawsUnderContention(t *testing.T) {
+	g, err := New(Options{MinWords: 1, MaxWords: 3, Delimiter: '-', Seed: 12})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if g.shards == nil {
+		t.Fatalf("expected contention shards")
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 0, 64)
+			for i := 0; i < 2000; i++ {
+				buf = g.GenerateInto(buf[:0], 0)
+				if n := bytes.Count(buf, []byte("-")) + 1; n < 1 || n > 4 {
+					t.Errorf("%q has %d words", buf, n)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		if err := g.SetWordRange(1+i%2, 4); err != nil {
+			t.Fatalf("SetWordRange: %v", err)
+		}
+	}
+	wg.Wait()
+
+	a, _ := New(Options{MinWords: 1, MaxWords: 3, Seed: 12})
+	b, _ := New(Options{MinWords: 1, MaxWords: 3, Seed: 12, SingleThreaded: true})
+	for i := 0; i < 200; i++ {
+		if x, y := a.Generate(0), b.Generate(0); x != y {
+			t.Fatalf("name %d = %q want the seeded %q", i, x, y)
+		}
+	}
+	if c, _ := New(Options{RecencyCooldown: 3}); c.shards != nil {
+		t.Fatalf("RecencyCooldown must keep every draw on the shared rng")
+	}
+}
+
+/**
+ * TestDelimiterStringJoinsWordsAndSlug checks a multi byte delimiter sizes dst exactly and parses back
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestDelimiterStringJoinsWordsAndSlug(t *testing.T) {
+	opts := Options{
+		IncludeGlobs:    []string{"adjectives/*.txt", "nouns/*.txt"},
+		Words:           3,
+		SlugLength:      4,
+		Delimiter:       '-',
+		DelimiterString: " :: ",
+		Seed:            7,
+	}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin, _ := New(opts)
+	for i := 0; i < 50; i++ {
+		name := g.Generate(0)
+		parts := strings.Split(name, " :: ")
+		if len(parts) != 4 || len(parts[3]) != 4 || strings.Contains(name, "-") && !strings.Contains(parts[0]+parts[1]+parts[2], "-") {
+			t.Fatalf("name %q not joined by the string delimiter", name)
+		}
+		words, slug, ok := Parse(name, opts)
+		if !ok || len(words) != 3 || slug != parts[3] {
+			t.Fatalf("Parse(%q) = %v %q %v", name, words, slug, ok)
+		}
+
+		// the pre sized length must be exact so a buffer of that capacity is reused
+		// the words match the twin while the crypto slug differs
+		buf := make([]byte, 0, len(name))
+		out := twin.GenerateInto(buf, 0)
+		if len(out) != len(name) || string(out[:len(name)-4]) != name[:len(name)-4] || &out[:1][0] != &buf[:1][0] {
+			t.Fatalf("GenerateInto %q reallocated or differs from %q", out, name)
+		}
+	}
+	if got := g.String(); !strings.Contains(got, `delim:" :: "`) {
+		t.Fatalf("String() = %q", got)
+	}
+
+	g, err = NewFromFiles(map[string][]string{"p": {"north star"}}, Options{AllowPhrases: true, PhraseJoin: true, DelimiterString: "::", Words: 2, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Generate(0); got != "north::star::north::star" {
+		t.Fatalf("phrase join = %q", got)
+	}
+}
+
+/**
+ * TestSlugDelimiterSeparatesSuffixes checks words keep Delimiter while the slug and counter use SlugDelimiter
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugDelimiterSeparatesSuffixes(t *testing.T) {
+	opts := Options{
+		IncludeGlobs:  []string{"adjectives/*.txt", "nouns/*.txt"},
+		ASCIIOnly:     true,
+		Words:         2,
+		SlugLength:    4,
+		Delimiter:     '_',
+		SlugDelimiter: '-',
+		Seed:          3,
+	}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin, _ := New(opts)
+	for i := 0; i < 100; i++ {
+		name := g.Generate(0)
+		cut := len(name) - 5
+		if name[cut] != '-' || strings.IndexByte(name[:cut], '_') < 0 || strings.ContainsRune(name[cut+1:], '_') {
+			t.Fatalf("name %q want words_joined-slug", name)
+		}
+		if words, slug, ok := Parse(name, opts); !ok || len(words) != 2 || slug != name[cut+1:] {
+			t.Fatalf("Parse(%q) = %v %q %v", name, words, slug, ok)
+		}
+		buf := make([]byte, 0, len(name))
+		if out := twin.GenerateInto(buf, 0); len(out) != len(name) || &out[:1][0] != &buf[:1][0] {
+			t.Fatalf("GenerateInto %q did not fit the presized length %d", out, len(name))
+		}
+	}
+
+	g, err = New(Options{IncludeGlobs: []string{"nouns/*.txt"}, SlugOnly: true, SlugLength: 3, CounterWidth: 2, DelimiterString: "::", SlugDelimiter: '.', Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := g.Generate(0)
+	if len(name) != 6 || name[3] != '.' {
+		t.Fatalf("slug only name %q want slug.counter", name)
+	}
+	buf := make([]byte, 0, 6)
+	if out := g.GenerateInto(buf, 0); len(out) != 6 || &out[:1][0] != &buf[:1][0] {
+		t.Fatalf("slug only GenerateInto %q reallocated", out)
+	}
+}
+
+/**
+ * TestNoAdjacentRepeatRedrawsOrTerminates checks overlapping lists skip river_river
+ * and lists holding only the repeated word still return a name
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNoAdjacentRepeatRedrawsOrTerminates(t *testing.T) {
+	files := map[string][]string{"a/x": {"river"}, "b/y": {"river"}}
+	opts := Options{
+		Strategy:         MergeByDir,
+		NoAdjacentRepeat: true,
+		Words:            2,
+		Seed:             2,
+	}
+	g, err := NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Generate(0); got != "river_river" {
+		t.Fatalf("single word lists = %q want the repeat kept", got)
+	}
+
+	opts.Lists = map[string][]string{"a/x": {"river", "stone"}, "b/y": {"river", "field", "marsh", "grove"}}
+	g, err = NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		if name := g.Generate(0); name == "river_river" {
+			t.Fatalf("draw %d repeated a word", i)
+		}
+		if picks, _ := g.GenerateIndices(0); g.lists[0][picks[0]] == g.lists[1][picks[1]] {
+			t.Fatalf("GenerateIndices repeated a word %v", picks)
+		}
+	}
+}
+
+/**
+ * TestEveryGenerationPathHonorsChecks checks reserved words and the previous name
+ * checks apply to the range detailed indices and word paths as well as Generate
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEveryGenerationPathHonorsChecks(t *testing.T) {
+	g, err := NewFromFiles(map[string][]string{"a": {"admin", "user"}}, Options{
+		ReservedWords: []string{"admin"},
+		Words:         1,
+		Seed:          8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		picks, _ := g.GenerateIndices(0)
+		w, werr := g.Word("a")
+		got := []string{g.GenerateRange(1, 1), g.GenerateDetailed(0).Name, g.RenderIndices(picks), w}
+		if werr != nil || slices.Contains(got, "admin") {
+			t.Fatalf("draw %d returned a reserved name %v %v", i, got, werr)
+		}
+	}
+
+	g, err = NewFromFiles(map[string][]string{"a": {"apple", "avocado", "banana", "blueberry"}}, Options{
+		AvoidCommonPrefix: 1,
+		Words:             1,
+		Seed:              8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := g.Generate(0)
+	for i := 0; i < 200; i++ {
+		var name string
+		switch i % 3 {
+		case 0:
+			name = g.GenerateRange(1, 1)
+		case 1:
+			name = g.GenerateDetailed(0).Name
+		default:
+			picks, _ := g.GenerateIndices(0)
+			name = g.RenderIndices(picks)
+		}
+		if name[0] == prev[0] {
+			t.Fatalf("draw %d %q shares its first rune with %q", i, name, prev)
+		}
+		prev = name
+	}
+}
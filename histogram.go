@@ -0,0 +1,87 @@
+package namemachine
+
+import "math/rand"
+
+// histogramSeed seeds the scratch rng so histograms repeat across calls
+const histogramSeed = 1
+
+/**
+ * LengthHistogram samples names and counts how many had each byte length
+ * handy for tuning MaxTotalLen or ui column widths the samples come from a
+ * scratch copy with its own rng so the generator stream counter and quota
+ * are left untouched
+ * @param nWords int optional override for number of words
+ * @param samples int number of names to sample
+ * @return map[int]int byte length to number of samples
+ */
+func (g *Generator) LengthHistogram(nWords, samples int) map[int]int {
+	hist := make(map[int]int)
+	if len(g.lists) == 0 {
+		return hist
+	}
+	s := g.shapeCopy(rand.New(rand.NewSource(histogramSeed)))
+	buf := make([]byte, 0, 128)
+	for i := 0; i < samples; i++ {
+		buf, _ = s.build(buf[:0], s.wordCount(nWords))
+		hist[len(buf)]++
+	}
+	return hist
+}
+
+/**
+ * shapeCopy returns a single threaded generator producing names of the same
+ * shape as g from rng with no counter store reservations or quota attached
+ * slugs come from crypto rand so a seeded slug stream or EntropySource is not consumed
+ * @param rng *rand.Rand random source for the copy
+ * @return *Generator scratch generator
+ */
+func (g *Generator) shapeCopy(rng *rand.Rand) *Generator {
+	g.lock()
+	defer g.unlock()
+	return &Generator{
+		lists:      g.lists,
+		ids:        g.ids,
+		delim:      g.delim,
+		slugDelim:  g.slugDelim,
+		wordDelims: g.wordDelims,
+		prefixIDs:  g.prefixIDs,
+		bareDelim:  g.bareDelim,
+		noDelim:    g.noDelim,
+
+		wordsExact:    g.wordsExact,
+		minWords:      g.minWords,
+		maxWords:      g.maxWords,
+		allowedCounts: g.allowedCounts,
+
+		slugLen:      g.slugLen,
+		slugAlphabet: g.slugAlphabet,
+		slugGroup:    g.slugGroup,
+		slugGroupSep: g.slugGroupSep,
+		slugProb:     g.slugProb,
+		slugSpecs:    g.slugSpecs,
+		specLen:      g.specLen,
+
+		rejectDegenerate: g.rejectDegenerate,
+
+		phraseJoin: g.phraseJoin,
+		upper:      g.upper,
+		upperRunes: g.upperRunes,
+		upperSlug:  g.upperSlug,
+		maxLen:     g.maxLen,
+		softHyphen: g.softHyphen,
+		slugOnly:   g.slugOnly,
+
+		counterWidth: g.counterWidth,
+		suffix:       g.suffix,
+		perm:         g.perm,
+		weights:      g.weights,
+		posWeighted:  g.posWeighted,
+		cooldown:     g.cooldown,
+		noRepeat:     g.noRepeat,
+		fixed:        g.fixed,
+		wordCase:     g.wordCase,
+
+		rng:            rng,
+		singleThreaded: true,
+	}
+}
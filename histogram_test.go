@@ -0,0 +1,67 @@
+package namemachine
+
+import (
+	"testing"
+)
+
+/**
+ * TestLengthHistogramWithinBounds checks sampled lengths fall between the shortest and
+ * longest possible names and that sampling leaves the main stream untouched
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestLengthHistogramWithinBounds(t *testing.T) {
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 2, SlugLength: 4, Seed: 21}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	twin, _ := New(opts)
+
+	bounds := func(l []string) (int, int) {
+		lo, hi := len(l[0]), len(l[0])
+		for _, w := range l {
+			lo, hi = min(lo, len(w)), max(hi, len(w))
+		}
+		return lo, hi
+	}
+	alo, ahi := bounds(g.lists[0])
+	nlo, nhi := bounds(g.lists[1])
+	minLen, maxLen := alo+nlo+2+4, ahi+nhi+2+4
+
+	hist := g.LengthHistogram(0, 2000)
+	total := 0
+	for n, c := range hist {
+		if n < minLen || n > maxLen {
+			t.Fatalf("length %d outside [%d,%d]", n, minLen, maxLen)
+		}
+		total += c
+	}
+	if total != 2000 || g.Position() != 0 {
+		t.Fatalf("total %d position %d", total, g.Position())
+	}
+	a, b := g.Generate(0), twin.Generate(0)
+	if a[:len(a)-4] != b[:len(b)-4] {
+		t.Fatalf("histogram disturbed the stream %q vs %q", a, b)
+	}
+}
+
+/**
+ * TestLengthHistogramCountsExtraSlugs checks Slugs segments are sampled like Generate builds them
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestLengthHistogramCountsExtraSlugs(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"ian", "bob", "eve"}}
+	g, err := NewFromFiles(files, Options{Words: 1, SlugLength: 5, Slugs: []SlugSpec{{Length: 3, Alphabet: "abc"}}, RejectDegenerateSlugs: true, Seed: 2})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	want := len(g.Generate(0))
+	if want != 3+1+5+1+3 {
+		t.Fatalf("generated length %d want 13", want)
+	}
+	if hist := g.LengthHistogram(0, 10); len(hist) != 1 || hist[want] != 10 {
+		t.Fatalf("histogram %v want map[%d:10]", hist, want)
+	}
+}
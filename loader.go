@@ -1,13 +1,20 @@
 package namemachine
 
 import (
+	"archive/tar"
 	"bufio"
 	"bytes"
+	"fmt"
+	"io"
 	"io/fs"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -17,59 +24,258 @@ import (
  */
 type fileWords map[string][]string // key: path "adjectives/age.txt"
 
+var (
+	embeddedOnce  sync.Once
+	embeddedFiles fileWords
+	embeddedErr   error
+)
+
 /**
  * loadAllFiles walks the embedded lists tree and loads every txt file
  * paths are stored with forward slashes for consistent glob matching
+ * the tree is parsed once per process with default parse settings and each call
+ * gets its own map over the shared word slices which must not be modified
  * @return fileWords map of file path to words and error
  */
 func loadAllFiles() (fileWords, error) {
+	embeddedOnce.Do(func() {
+		embeddedFiles, embeddedErr = loadFiles(listsFS, "lists", Options{})
+	})
+	if embeddedErr != nil {
+		return nil, embeddedErr
+	}
+	out := make(fileWords, len(embeddedFiles))
+	for name, words := range embeddedFiles {
+		out[name] = words
+	}
+	return out, nil
+}
+
+/**
+ * AvailableLists reports the ids present in the embedded corpus in sorted order
+ * both directory ids such as nouns and file ids such as nouns/birds.txt are
+ * included so either can be used in globs or as Include and Exclude keys
+ * @return []string list ids
+ */
+func AvailableLists() []string {
+	files, err := loadAllFiles()
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	var ids []string
+	for name := range files {
+		ids = append(ids, name)
+		if dir := path.Dir(name); dir != "." {
+			if _, ok := seen[dir]; !ok {
+				seen[dir] = struct{}{}
+				ids = append(ids, dir)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+/**
+ * loadFiles walks root inside fsys and loads every txt file using the parse options
+ * keys are relative to root with forward slashes for consistent glob matching
+ * @param fsys fs.FS filesystem to walk
+ * @param root string directory inside fsys holding the lists
+ * @param opts Options parse settings such as the trim cutset
+ * @return fileWords map of file path to words and error
+ */
+func loadFiles(fsys fs.FS, root string, opts Options) (fileWords, error) {
 	out := make(fileWords)
 
-	// walk the embedded filesystem rooted at ./lists
-	err := fs.WalkDir(listsFS, "lists", func(p string, d fs.DirEntry, err error) error {
+	// walk the filesystem rooted at root
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
 			return err
 		}
 
 		// only process txt files
-		if filepath.Ext(p) != ".txt" {
+		if path.Ext(p) != ".txt" {
 			return nil
 		}
 
-		// read file bytes from the embed fs
-		b, err := listsFS.ReadFile(p)
+		// read file bytes from the fs
+		b, err := fs.ReadFile(fsys, p)
 		if err != nil {
 			return err
 		}
 
 		// store with slash separators for matching
-		rel := strings.TrimPrefix(p, "lists/")
+		rel := strings.TrimPrefix(p, root+"/")
 		rel = filepath.ToSlash(rel)
-		out[rel] = parseWordFile(b)
+		out[rel] = parseWordFile(b, opts)
 		return nil
 	})
 	return out, err
 }
 
+/**
+ * addExtraFS walks each Options.ExtraFS from its root and adds its txt files
+ * to files under the same slash paths a later filesystem replaces an earlier
+ * one and any of them replaces an embedded file at the same path
+ * @param files fileWords loaded files owned by the caller updated in place
+ * @param opts Options holding ExtraFS and the parse settings
+ * @return error naming the filesystem that could not be walked
+ */
+func addExtraFS(files fileWords, opts Options) error {
+	for i, fsys := range opts.ExtraFS {
+		extra, err := loadFiles(fsys, ".", opts)
+		if err != nil {
+			return fmt.Errorf("ExtraFS[%d]: %w", i, err)
+		}
+		for name, words := range extra {
+			files[name] = words
+		}
+	}
+	return nil
+}
+
+/**
+ * addLists adds the Options.Lists entries to files as if each were a file
+ * keyed by its list id an entry replaces any loaded file at the same path
+ * @param files fileWords loaded files owned by the caller updated in place
+ * @param opts Options holding Lists
+ * @return void
+ */
+func addLists(files fileWords, opts Options) {
+	for id, words := range opts.Lists {
+		files[id] = append([]string(nil), words...)
+	}
+}
+
+/**
+ * withListKeys adds every Options.Lists id missing from names
+ * so the entries are selected whatever the globs say
+ * @param names []string sorted file names picked by the globs
+ * @param lists map[string][]string Options.Lists
+ * @return []string sorted names including every list id
+ */
+func withListKeys(names []string, lists map[string][]string) []string {
+	out := append([]string(nil), names...)
+	for id := range lists {
+		if !slices.Contains(names, id) {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+/**
+ * loadTar reads every regular txt entry of a tar stream using the parse options
+ * keys are the entry paths cleaned of any leading ./ or /
+ * @param r io.Reader tar stream
+ * @param opts Options parse settings such as the trim cutset
+ * @return fileWords map of entry path to words and error for a corrupt archive
+ */
+func loadTar(r io.Reader, opts Options) (fileWords, error) {
+	out := make(fileWords)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tar: %w", err)
+		}
+		if h.Typeflag != tar.TypeReg || path.Ext(h.Name) != ".txt" {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read tar entry %q: %w", h.Name, err)
+		}
+		name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(h.Name)), "/")
+		out[name] = parseWordFile(b, opts)
+	}
+}
+
 /**
  * parseWordFile splits a text file into trimmed non empty non comment lines
  * comment lines start with hash
+ * a line wrapped in double quotes is taken verbatim minus the quotes
  * @param b []byte file contents
+ * @param opts Options parse settings such as the trim cutset
  * @return []string words one per line in file order
  */
-func parseWordFile(b []byte) []string {
+func parseWordFile(b []byte, opts Options) []string {
+	var words []string
+	scanWordLines(b, opts, func(_ int, w string) {
+		words = append(words, w)
+	})
+	return words
+}
+
+/**
+ * scanWordLines walks a word file and calls fn for every token with its line number
+ * shared by the loader and the validator so both agree on what a token is
+ * @param b []byte file contents
+ * @param opts Options parse settings such as the trim cutset
+ * @param fn func(int, string) receives the one based line number and token
+ * @return void
+ */
+func scanWordLines(b []byte, opts Options, fn func(line int, w string)) {
 	sc := bufio.NewScanner(bytes.NewReader(b))
 	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
-	var words []string
+	n := 0
 	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
+		n++
+		line := trimToken(sc.Text(), opts)
+
+		// quoted tokens skip comment handling so "#1" or "co-op" survive as written
+		if w, ok := unquoteToken(line); ok {
+			if w != "" {
+				fn(n, w)
+			}
+			continue
+		}
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
-		words = append(words, line)
+		fn(n, line)
+	}
+}
+
+/**
+ * trimToken trims a raw line with the configured cutset
+ * whitespace by default a custom cutset replaces it and NoTrim keeps the line as is
+ * @param line string raw line
+ * @param opts Options trim settings
+ * @return string trimmed line
+ */
+func trimToken(line string, opts Options) string {
+	switch {
+	case opts.NoTrim:
+		return line
+	case opts.TrimCutset != "":
+		return strings.Trim(line, opts.TrimCutset)
+	default:
+		return strings.TrimSpace(line)
 	}
-	return words
+}
+
+/**
+ * unquoteToken strips surrounding double quotes from a trimmed line
+ * a doubled quote inside the value stands for one literal quote like csv
+ * @param line string trimmed input line
+ * @return string unquoted value and bool true when the line was quoted
+ */
+func unquoteToken(line string) (string, bool) {
+	if len(line) < 2 || line[0] != '"' || line[len(line)-1] != '"' {
+		return "", false
+	}
+	inner := line[1 : len(line)-1]
+	if !strings.Contains(inner, `"`) {
+		return inner, true
+	}
+	return strings.ReplaceAll(inner, `""`, `"`), true
 }
 
 /**
@@ -128,47 +334,362 @@ func globFilter(files fileWords, includes, excludes []string) []string {
 	return kept
 }
 
+/**
+ * listNameGlobs translates ListNames into include globs over files
+ * a name selects every file under that directory at any depth such as
+ * adjectives or names/cities and a path without .txt selects that one file
+ * path.Match has no ** so one glob is emitted per directory depth in use
+ * @param files fileWords loaded files
+ * @param names []string logical list names
+ * @return []string include globs and error naming a list that matches no file
+ */
+func listNameGlobs(files fileWords, names []string) ([]string, error) {
+	depth := 0
+	for n := range files {
+		depth = max(depth, strings.Count(n, "/"))
+	}
+	var out []string
+	for _, name := range names {
+		name = strings.Trim(path.Clean(name), "/")
+		globs := []string{name + ".txt"}
+		for d, dir := 0, name; d < depth; d++ {
+			dir += "/*"
+			globs = append(globs, dir+".txt")
+		}
+		matched := false
+		for n := range files {
+			for _, g := range globs {
+				if ok, _ := path.Match(g, n); ok {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("list name %q matches no files", name)
+		}
+		out = append(out, globs...)
+	}
+	return out, nil
+}
+
+/**
+ * orderByGlobs reorders selected names by the first include glob each one matches
+ * names under the same glob keep their lexical order
+ * @param names []string sorted names from globFilter
+ * @param includes []string include globs in the order the user wrote them
+ * @return []string names ordered by glob rank
+ */
+func orderByGlobs(names, includes []string) []string {
+	rank := make(map[string]int, len(names))
+	for _, n := range names {
+		rank[n] = len(includes)
+		for i, inc := range includes {
+			if ok, _ := path.Match(inc, n); ok {
+				rank[n] = i
+				break
+			}
+		}
+	}
+	out := append([]string(nil), names...)
+	sort.SliceStable(out, func(i, j int) bool { return rank[out[i]] < rank[out[j]] })
+	return out
+}
+
 /**
  * normalizeAndFilter applies lowercasing ascii filtering length bounds and dedup
+ * tokens with internal whitespace are kept unless DropPhrases is set
+ * dedup is skipped when in file duplicates are allowed to act as weights
  * order of first occurrence is preserved
  * @param words []string input tokens
- * @param lowercase bool convert to lower case when true
- * @param asciiOnly bool drop tokens with non ascii bytes when true
- * @param minLen int minimum length to keep zero means no minimum
- * @param maxLen int maximum length to keep zero means no maximum
+ * @param opts Options normalization and filter settings
  * @return []string normalized filtered and deduplicated words
  */
-func normalizeAndFilter(words []string, lowercase, asciiOnly bool, minLen, maxLen int) []string {
+func normalizeAndFilter(words []string, opts Options) []string {
 	dst := words[:0]
 	for _, w := range words {
-		if lowercase {
+		if opts.Lowercase {
 			w = strings.ToLower(w)
 		}
-		if asciiOnly && !isASCII(w) {
+		if opts.StripChars != "" {
+			if w = stripChars(w, opts.StripChars); w == "" {
+				continue
+			}
+		}
+		if opts.ASCIIOnly && !isASCII(w) {
+			continue
+		}
+		if isPhrase(w) {
+			if opts.DropPhrases {
+				continue
+			}
+			if opts.AllowPhrases && opts.CamelCasePhrases {
+				w = camelCase(w)
+			}
+		}
+		if opts.EnvVar && !isEnvWord(w, opts.AllowPhrases && opts.PhraseJoin) {
+			continue
+		}
+		if opts.RequireVowel && !hasVowel(w) {
 			continue
 		}
-		if minLen > 0 && len(w) < minLen {
+		if opts.MinLen > 0 && len(w) < opts.MinLen {
 			continue
 		}
-		if maxLen > 0 && len(w) > maxLen {
+		if opts.MaxLen > 0 && len(w) > opts.MaxLen {
 			continue
 		}
 		dst = append(dst, w)
 	}
+	if opts.AllowInFileDuplicates {
+		return dst
+	}
+	return dedupWords(dst, opts)
+}
+
+/**
+ * dedupWords drops repeats in place keeping the first appearance
+ * DedupNone keeps every word
+ * @param words []string normalized words
+ * @param opts Options dedup settings
+ * @return []string words without repeats
+ */
+func dedupWords(words []string, opts Options) []string {
+	if opts.DedupScope == DedupNone {
+		return words
+	}
+	seen := make(map[string]struct{}, len(words))
+	out := words[:0]
+	for _, w := range words {
+		k := dedupKey(w, opts)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, w)
+	}
+	return out
+}
+
+/**
+ * stripChars removes every rune of set from w
+ * @param w string word
+ * @param set string runes to remove
+ * @return string word without them unchanged when none occur
+ */
+func stripChars(w, set string) string {
+	if !strings.ContainsAny(w, set) {
+		return w
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(set, r) {
+			return -1
+		}
+		return r
+	}, w)
+}
+
+/**
+ * dedupKey is the form a word is compared under when removing duplicates
+ * FoldCaseForDedup lowercases the key only so output keeps its casing
+ * @param w string word as emitted
+ * @param opts Options dedup settings
+ * @return string comparison key
+ */
+func dedupKey(w string, opts Options) string {
+	if opts.FoldCaseForDedup {
+		return strings.ToLower(w)
+	}
+	return w
+}
+
+/**
+ * mergeFiles concatenates the words of several files into one normalized list
+ * with in file duplicates allowed a repeat inside one file is kept as weight
+ * while a word already contributed by an earlier file is still dropped
+ * @param files fileWords map of all loaded files
+ * @param names []string files feeding this list in order
+ * @param opts Options normalization settings
+ * @return []string merged words
+ */
+func mergeFiles(files fileWords, names []string, opts Options) []string {
+	acc := make([]string, 0, 1024)
+	if !opts.AllowInFileDuplicates {
+		for _, n := range names {
+			acc = append(acc, scopedWords(files, n, opts)...)
+		}
+		return dedupWords(acc, opts)
+	}
+
+	owner := make(map[string]int)
+	for i, n := range names {
+		words := scopedWords(files, n, opts)
+		for _, w := range words {
+			k := dedupKey(w, opts)
+			if j, ok := owner[k]; ok && j != i {
+				continue
+			}
+			owner[k] = i
+			acc = append(acc, w)
+		}
+	}
+	return acc
+}
+
+/**
+ * scopedWords returns the normalized words of one file after its Include and Exclude globs
+ * keys are the ids mergeLists builds a file such as nouns/fish.txt or its
+ * directory such as nouns and patterns use path.Match against the words as
+ * normalizeAndFilter leaves them so they run before any cross list dedup
+ * @param files fileWords map of all loaded files
+ * @param name string file name
+ * @param opts Options holding the Include and Exclude maps
+ * @return []string a fresh slice of the file words
+ */
+func scopedWords(files fileWords, name string, opts Options) []string {
+	// clone first since normalization works in place and files may be shared
+	words := normalizeAndFilter(append([]string(nil), files[name]...), opts)
+	return filterGlobs(words, scopedGlobs(opts.Include, name, opts), scopedGlobs(opts.Exclude, name, opts))
+}
 
-	// stable dedup keep first appearance
-	seen := make(map[string]struct{}, len(dst))
-	out := dst[:0]
-	for _, w := range dst {
-		if _, ok := seen[w]; ok {
+/**
+ * filterGlobs keeps the words matching inc when it is set and none of exc in place
+ * @param words []string words owned by the caller
+ * @param inc []string include patterns empty keeps everything
+ * @param exc []string exclude patterns
+ * @return []string kept words sharing the input array
+ */
+func filterGlobs(words, inc, exc []string) []string {
+	if len(inc) == 0 && len(exc) == 0 {
+		return words
+	}
+	out := words[:0]
+	for _, w := range words {
+		if len(inc) > 0 && !matchAnyGlob(inc, w) {
+			continue
+		}
+		if matchAnyGlob(exc, w) {
 			continue
 		}
-		seen[w] = struct{}{}
 		out = append(out, w)
 	}
 	return out
 }
 
+/**
+ * filterListIDs applies Include and Exclude keyed by a built list id such as the
+ * MergeSingle all an ExtraLists id or misc file and directory keys were already
+ * applied per file and running a filter again keeps the same words
+ * @param lists [][]string built lists filtered in place
+ * @param ids []string ids aligned with lists
+ * @param opts Options holding the Include and Exclude maps
+ * @return void
+ */
+func filterListIDs(lists [][]string, ids []string, opts Options) {
+	if len(opts.Include) == 0 && len(opts.Exclude) == 0 {
+		return
+	}
+	for i, id := range ids {
+		lists[i] = filterGlobs(lists[i], opts.Include[id], opts.Exclude[id])
+	}
+}
+
+/**
+ * scopedGlobs collects the patterns keyed by a file or its directory
+ * @param m map[string][]string include or exclude map
+ * @param name string file name
+ * @param opts Options for the root bucket name
+ * @return []string patterns that apply to the file
+ */
+func scopedGlobs(m map[string][]string, name string, opts Options) []string {
+	byFile, byDir := m[name], m[bucketID(name, opts)]
+	if len(byDir) == 0 {
+		return byFile
+	}
+	if len(byFile) == 0 {
+		return byDir
+	}
+	out := make([]string, 0, len(byFile)+len(byDir))
+	out = append(out, byFile...)
+	return append(out, byDir...)
+}
+
+/**
+ * matchAnyGlob reports whether s matches one of the patterns
+ * malformed patterns never match
+ * @param globs []string patterns for path.Match
+ * @param s string candidate
+ * @return bool true on the first match
+ */
+func matchAnyGlob(globs []string, s string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * hasVowel reports whether a word holds an ascii vowel counting y
+ * so rhythm passes while tokens like pwn or 3d do not
+ * @param w string word
+ * @return bool true when at least one vowel is present
+ */
+func hasVowel(w string) bool {
+	return strings.ContainsAny(w, "aeiouyAEIOUY")
+}
+
+/**
+ * camelCase joins the fields of a phrase uppercasing the first letter of each
+ * north star becomes NorthStar the rest of each field is left as written
+ * @param s string phrase with internal whitespace
+ * @return string single camel cased token
+ */
+func camelCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, f := range strings.Fields(s) {
+		r, size := utf8.DecodeRuneInString(f)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(f[size:])
+	}
+	return b.String()
+}
+
+/**
+ * isPhrase reports whether a trimmed token holds internal spaces or tabs
+ * @param s string input
+ * @return bool true when the token is a multi word phrase
+ */
+func isPhrase(s string) bool {
+	return strings.ContainsAny(s, " \t")
+}
+
+/**
+ * isEnvWord reports whether a token is safe inside an environment variable name
+ * it must start with a letter and hold only ascii letters and digits
+ * spaces are allowed when phrases are joined since they become underscores
+ * @param s string input
+ * @param joinSpaces bool allow spaces that will be rewritten to the delimiter
+ * @return bool true when the token is env var safe
+ */
+func isEnvWord(s string, joinSpaces bool) bool {
+	if s == "" || !(s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z') {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		b := s[i]
+		if (b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') {
+			continue
+		}
+		if joinSpaces && b == ' ' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 /**
  * isASCII returns true when the string has only ascii bytes
  * also verifies the string is valid utf8
@@ -188,80 +709,309 @@ func isASCII(s string) bool {
 /**
  * mergeLists builds word lists from selected files using the requested strategy
  * can merge by directory single list or by file then optionally cross deduplicate
- * returns both the lists and their identifiers
+ * returns both the lists and their identifiers lists left empty are dropped
  * @param files fileWords map of all loaded files
  * @param names []string selected file names after glob filtering
  * @param opts Options options controlling normalization strategy and dedup
  * @return [][]string merged lists and []string their ids
  */
 func mergeLists(files fileWords, names []string, opts Options) (lists [][]string, ids []string) {
+	lists, ids, _ = buildLists(files, names, opts)
+	return lists, ids
+}
+
+/**
+ * buildLists is mergeLists that also reports the ids of lists dropped for being empty
+ * a list can empty out through normalization filters per list word filters or cross dedup
+ * @param files fileWords map of all loaded files
+ * @param names []string selected file names after glob filtering
+ * @param opts Options options controlling normalization strategy and dedup
+ * @return [][]string merged lists []string their ids and []string dropped ids
+ */
+func buildLists(files fileWords, names []string, opts Options) (lists [][]string, ids, dropped []string) {
 	switch opts.Strategy {
 
 	case MergeByDir:
 		// group by first directory component for example adjectives or names
+		// keys keep first appearance order which follows the globs when preserved
 		buckets := map[string][]string{}
+		var keys []string
 		for _, n := range names {
-			dir := path.Dir(n)
+			dir := bucketID(n, opts)
+			if _, ok := buckets[dir]; !ok {
+				keys = append(keys, dir)
+			}
 			buckets[dir] = append(buckets[dir], n)
 		}
 
-		// sort keys for stable output
-		keys := make([]string, 0, len(buckets))
-		for k := range buckets {
-			keys = append(keys, k)
+		// sort keys for stable output unless the caller wants glob order
+		if !opts.PreserveGlobOrder {
+			sort.Strings(keys)
 		}
-		sort.Strings(keys)
 
 		// accumulate words per bucket & normalize
 		for _, k := range keys {
-			acc := make([]string, 0, 1024)
-			for _, f := range buckets[k] {
-				acc = append(acc, files[f]...)
-			}
-			acc = normalizeAndFilter(acc, opts.Lowercase, opts.ASCIIOnly, opts.MinLen, opts.MaxLen)
-			if len(acc) > 0 {
-				lists = append(lists, acc)
-				ids = append(ids, k)
-			}
+			lists = append(lists, mergeFiles(files, buckets[k], opts))
+			ids = append(ids, k)
 		}
 
 	case MergeSingle:
 		// flatten all selected files into one big list then normalize
-		acc := make([]string, 0, 4096)
-		for _, n := range names {
-			acc = append(acc, files[n]...)
-		}
-		acc = normalizeAndFilter(acc, opts.Lowercase, opts.ASCIIOnly, opts.MinLen, opts.MaxLen)
-		if len(acc) > 0 {
-			lists = append(lists, acc)
+		if len(names) > 0 {
+			lists = append(lists, mergeFiles(files, names, opts))
 			ids = append(ids, "all")
 		}
 
 	default: // MergeByFile
 		// keep one list per file after normalization
 		for _, n := range names {
-			w := normalizeAndFilter(files[n], opts.Lowercase, opts.ASCIIOnly, opts.MinLen, opts.MaxLen)
-			if len(w) > 0 {
-				lists = append(lists, w)
-				ids = append(ids, n)
-			}
+			lists = append(lists, scopedWords(files, n, opts))
+			ids = append(ids, n)
 		}
 	}
 
-	// optional cross list dedup remove tokens seen in earlier lists
-	if opts.CrossDedup && len(lists) > 1 {
-		globSeen := make(map[string]int)
+	lists, ids = mergeExtraLists(lists, ids, opts)
+	filterListIDs(lists, ids, opts)
+
+	// optional cross list dedup remove tokens owned by earlier lists
+	// repeats within one list survive so AllowInFileDuplicates keeps its weight
+	if opts.crossDedup() && len(lists) > 1 {
+		owner := make(map[string]int)
 		for i := range lists {
 			dst := lists[i][:0]
 			for _, w := range lists[i] {
-				if _, ok := globSeen[w]; ok {
+				k := dedupKey(w, opts)
+				if j, ok := owner[k]; ok && j != i {
 					continue
 				}
-				globSeen[w] = 1
+				owner[k] = i
 				dst = append(dst, w)
 			}
 			lists[i] = dst
 		}
 	}
+	var folded bool
+	if lists, ids, folded = foldSmall(lists, ids, opts); folded {
+		n := len(ids) - 1
+		filterListIDs(lists[n:], ids[n:], opts)
+	}
+	return dropEmpty(lists, ids)
+}
+
+// miscListID names the catch all list built from lists below MinListSize
+const miscListID = "misc"
+
+/**
+ * foldSmall folds or drops non empty lists with fewer than MinListSize words
+ * folded words join one misc list appended after the surviving lists
+ * its id is misc or misc_2 misc_3 and so on when a kept list already uses it
+ * @param lists [][]string built lists
+ * @param ids []string ids of the built lists
+ * @param opts Options MinListSize OnSmallList and dedup settings
+ * @return [][]string lists []string ids after folding and bool true when a misc list was appended
+ */
+func foldSmall(lists [][]string, ids []string, opts Options) ([][]string, []string, bool) {
+	if opts.MinListSize <= 0 {
+		return lists, ids, false
+	}
+	var misc []string
+	seen := make(map[string]struct{})
+	kept, keptIDs := lists[:0], ids[:0]
+	for i, l := range lists {
+		if len(l) == 0 || len(l) >= opts.MinListSize {
+			kept = append(kept, l)
+			keptIDs = append(keptIDs, ids[i])
+			continue
+		}
+		if opts.OnSmallList == SmallListDrop {
+			continue
+		}
+		for _, w := range l {
+			k := dedupKey(w, opts)
+			if _, ok := seen[k]; ok && !opts.AllowInFileDuplicates && opts.DedupScope != DedupNone {
+				continue
+			}
+			seen[k] = struct{}{}
+			misc = append(misc, w)
+		}
+	}
+	if len(misc) == 0 {
+		return kept, keptIDs, false
+	}
+	id := miscListID
+	for n := 2; slices.Contains(keptIDs, id); n++ {
+		id = miscListID + "_" + strconv.Itoa(n)
+	}
+	return append(kept, misc), append(keptIDs, id), true
+}
+
+/**
+ * bucketID is the by dir list id for a file its directory with top level files
+ * under RootBucketName when set instead of the awkward dot
+ * @param name string file name
+ * @param opts Options holding RootBucketName
+ * @return string bucket id
+ */
+func bucketID(name string, opts Options) string {
+	dir := path.Dir(name)
+	if dir == "." && opts.RootBucketName != "" {
+		return opts.RootBucketName
+	}
+	return dir
+}
+
+/**
+ * dropEmpty removes empty lists and reports which ids went away
+ * @param lists [][]string built lists
+ * @param ids []string ids of the built lists
+ * @return [][]string kept lists []string kept ids and []string dropped ids
+ */
+func dropEmpty(lists [][]string, ids []string) ([][]string, []string, []string) {
+	var dropped []string
+	kept, keptIDs := lists[:0], ids[:0]
+	for i := range lists {
+		if len(lists[i]) == 0 {
+			dropped = append(dropped, ids[i])
+			continue
+		}
+		kept = append(kept, lists[i])
+		keptIDs = append(keptIDs, ids[i])
+	}
+	return kept, keptIDs, dropped
+}
+
+/**
+ * mergeExtraLists folds user supplied lists into the built ones by id
+ * same id lists combine per the merge policy and new ids are appended sorted
+ * user words pass through the same normalization as file words
+ * lists left empty are dropped later by buildLists
+ * @param lists [][]string built lists
+ * @param ids []string ids of the built lists
+ * @param opts Options carrying ExtraLists and MergePolicy
+ * @return [][]string combined lists and []string their ids
+ */
+func mergeExtraLists(lists [][]string, ids []string, opts Options) ([][]string, []string) {
+	if len(opts.ExtraLists) == 0 {
+		return lists, ids
+	}
+
+	keys := make([]string, 0, len(opts.ExtraLists))
+	for k := range opts.ExtraLists {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, id := range keys {
+		extra := normalizeAndFilter(append([]string(nil), opts.ExtraLists[id]...), opts)
+
+		at := -1
+		for i, existing := range ids {
+			if existing == id {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			lists = append(lists, extra)
+			ids = append(ids, id)
+			continue
+		}
+
+		switch opts.MergePolicy {
+		case PolicyReplace:
+			lists[at] = extra
+		case PolicyAppend:
+			lists[at] = append(lists[at], extra...)
+		default: // PolicyAppendDedup
+			have := make(map[string]struct{}, len(lists[at]))
+			for _, w := range lists[at] {
+				have[dedupKey(w, opts)] = struct{}{}
+			}
+			for _, w := range extra {
+				k := dedupKey(w, opts)
+				if _, ok := have[k]; !ok {
+					have[k] = struct{}{}
+					lists[at] = append(lists[at], w)
+				}
+			}
+		}
+	}
 	return lists, ids
 }
+
+/**
+ * capLists keeps at most max lists chosen by a seeded shuffle
+ * the kept lists stay in their original relative order
+ * the choice uses its own rng so the generator stream is unaffected
+ * @param lists [][]string built lists
+ * @param ids []string ids of the built lists
+ * @param max int cap zero or less keeps everything
+ * @param seed int64 seed for the selection
+ * @param stable bool draw from the StableRandom source
+ * @return [][]string kept lists and []string their ids
+ */
+func capLists(lists [][]string, ids []string, max int, seed int64, stable bool) ([][]string, []string) {
+	if max <= 0 || len(lists) <= max {
+		return lists, ids
+	}
+
+	pick := newRand(seed, stable).Perm(len(lists))[:max]
+	sort.Ints(pick)
+
+	keptLists := make([][]string, 0, max)
+	keptIDs := make([]string, 0, max)
+	for _, i := range pick {
+		keptLists = append(keptLists, lists[i])
+		keptIDs = append(keptIDs, ids[i])
+	}
+	return keptLists, keptIDs
+}
+
+/**
+ * sampleLists down samples every list longer than max to exactly max words
+ * selection sampling keeps file order and each list draws from its own rng
+ * seeded from seed and its position so results repeat for a seed
+ * @param lists [][]string built lists modified in place
+ * @param max int cap zero or less keeps everything
+ * @param seed int64 seed for the sampling
+ * @param stable bool draw from the StableRandom source
+ * @return [][]string lists with large ones replaced by fresh smaller slices
+ */
+func sampleLists(lists [][]string, max int, seed int64, stable bool) [][]string {
+	if max <= 0 {
+		return lists
+	}
+	for i, l := range lists {
+		if len(l) <= max {
+			continue
+		}
+		r := newRand(seed+int64(i), stable)
+		out := make([]string, 0, max)
+		for j, w := range l {
+			need, left := max-len(out), len(l)-j
+			if r.Intn(left) < need {
+				out = append(out, w)
+			}
+		}
+		lists[i] = out
+	}
+	return lists
+}
+
+/**
+ * shuffleLists reorders every list with a rng seeded from seed and its position
+ * each list is copied first so cached corpus slices are never reordered
+ * @param lists [][]string built lists modified in place
+ * @param seed int64 seed for the shuffle
+ * @param stable bool draw from the StableRandom source
+ * @return [][]string lists with shuffled copies
+ */
+func shuffleLists(lists [][]string, seed int64, stable bool) [][]string {
+	for i, l := range lists {
+		r := newRand(int64(mix64(uint64(seed)+uint64(i))), stable)
+		out := append([]string(nil), l...)
+		r.Shuffle(len(out), func(a, b int) { out[a], out[b] = out[b], out[a] })
+		lists[i] = out
+	}
+	return lists
+}
@@ -0,0 +1,1009 @@
+package namemachine
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io/fs"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+/**
+ * TestPhrasesPreservedAndJoined covers phrase tokens kept by default dropped with DropPhrases
+ * and rewritten to the delimiter inside a single word slot when PhraseJoin is set
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPhrasesPreservedAndJoined(t *testing.T) {
+	files := fileWords{"stars/names.txt": {"north star", "vega"}}
+	names := []string{"stars/names.txt"}
+
+	// phrases survive by default with their space intact as the loader always kept them
+	lists, _ := mergeLists(files, names, Options{Strategy: MergeByFile})
+	if !reflect.DeepEqual(lists[0], []string{"north star", "vega"}) {
+		t.Fatalf("default phrase handling got %v", lists[0])
+	}
+	lists, _ = mergeLists(files, names, Options{Strategy: MergeByFile, AllowPhrases: true})
+	if !reflect.DeepEqual(lists[0], []string{"north star", "vega"}) {
+		t.Fatalf("allowed phrases got %v", lists[0])
+	}
+
+	// dropping is opt in
+	lists, _ = mergeLists(files, names, Options{Strategy: MergeByFile, DropPhrases: true})
+	if !reflect.DeepEqual(lists[0], []string{"vega"}) {
+		t.Fatalf("DropPhrases got %v want [vega]", lists[0])
+	}
+
+	// preserved spaces come out as is
+	g := &Generator{
+		lists:      [][]string{{"north star"}},
+		delim:      []byte("_"),
+		wordsExact: 1,
+		rng:        rand.New(rand.NewSource(1)),
+	}
+	if got := g.Generate(0); got != "north star" {
+		t.Fatalf("preserved phrase got %q", got)
+	}
+
+	// joined phrases take the delimiter and still fill one slot
+	g.phraseJoin = true
+	g.wordsExact = 2
+	g.lists = [][]string{{"north star"}, {"vega"}}
+	if got := g.Generate(0); got != "north_star_vega" {
+		t.Fatalf("joined phrase got %q", got)
+	}
+}
+
+/**
+ * TestParseWordFileQuoted checks quoted tokens keep comment chars delimiters and spaces verbatim
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestParseWordFileQuoted(t *testing.T) {
+	in := []byte("# header\nplain\n\"#hashtag\"\n\"co-op\"\n\"snake_case\"\n  \"  padded  \"  \n\"say \"\"hi\"\"\"\n\"\"\n\"open\n")
+	got := parseWordFile(in, Options{})
+	want := []string{"plain", "#hashtag", "co-op", "snake_case", "  padded  ", `say "hi"`, `"open`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseWordFile quoted got %q want %q", got, want)
+	}
+}
+
+/**
+ * TestParseWordFileTrimCutset trims bullets and trailing commas with a custom cutset
+ * and keeps lines untouched with NoTrim
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestParseWordFileTrimCutset(t *testing.T) {
+	in := []byte("- alpha,\n* beta,,\n \n-gamma\n")
+
+	got := parseWordFile(in, Options{TrimCutset: " -*,"})
+	want := []string{"alpha", "beta", "gamma"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("custom cutset got %q want %q", got, want)
+	}
+
+	got = parseWordFile([]byte(" alpha \nbeta\n"), Options{NoTrim: true})
+	want = []string{" alpha ", "beta"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("no trim got %q want %q", got, want)
+	}
+}
+
+/**
+ * TestAllowInFileDuplicates keeps in file repeats as weights and still drops repeats from other files
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestAllowInFileDuplicates(t *testing.T) {
+	newFiles := func() fileWords {
+		return fileWords{
+			"a/x.txt": {"foo", "foo", "foo", "bar"},
+			"a/y.txt": {"bar", "baz", "baz"},
+		}
+	}
+	names := []string{"a/x.txt", "a/y.txt"}
+
+	// default dedups inside each file
+	lists, _ := mergeLists(newFiles(), names, Options{Strategy: MergeByFile})
+	if !reflect.DeepEqual(lists[0], []string{"foo", "bar"}) {
+		t.Fatalf("default by file got %v", lists[0])
+	}
+
+	// repeats survive by file when allowed
+	lists, _ = mergeLists(newFiles(), names, Options{Strategy: MergeByFile, AllowInFileDuplicates: true})
+	if !reflect.DeepEqual(lists[0], []string{"foo", "foo", "foo", "bar"}) {
+		t.Fatalf("allowed by file got %v", lists[0])
+	}
+
+	// by dir keeps in file repeats but bar from y is already owned by x
+	lists, _ = mergeLists(newFiles(), names, Options{Strategy: MergeByDir, AllowInFileDuplicates: true})
+	want := []string{"foo", "foo", "foo", "bar", "baz", "baz"}
+	if !reflect.DeepEqual(lists[0], want) {
+		t.Fatalf("allowed by dir got %v want %v", lists[0], want)
+	}
+
+	// cross list dedup drops only what an earlier list owns and keeps in list weight
+	lists, _ = mergeLists(newFiles(), names, Options{Strategy: MergeByFile, AllowInFileDuplicates: true, CrossDedup: true})
+	if want := [][]string{{"foo", "foo", "foo", "bar"}, {"baz", "baz"}}; !reflect.DeepEqual(lists, want) {
+		t.Fatalf("cross dedup with duplicates got %v want %v", lists, want)
+	}
+	lists, _ = mergeLists(newFiles(), names, Options{
+		Strategy:              MergeByFile,
+		AllowInFileDuplicates: true,
+		CrossDedup:            true,
+		MergePolicy:           PolicyAppend,
+		ExtraLists:            map[string][]string{"a/y.txt": {"qux", "qux"}},
+	})
+	if want := []string{"baz", "baz", "qux", "qux"}; !reflect.DeepEqual(lists[1], want) {
+		t.Fatalf("PolicyAppend weight got %v want %v", lists[1], want)
+	}
+}
+
+/**
+ * TestMergePolicyWithEmbeddedAdjectives combines a user adjectives list with the embedded bucket
+ * under each policy and checks unknown ids become new lists
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMergePolicyWithEmbeddedAdjectives(t *testing.T) {
+	files, err := loadAllFiles()
+	if err != nil {
+		t.Fatalf("loadAllFiles: %v", err)
+	}
+	names := globFilter(files, []string{"adjectives/*.txt"}, nil)
+	base, _ := mergeLists(files, names, Options{Strategy: MergeByDir})
+	existing := base[0][0] // a word already in the embedded bucket
+
+	build := func(p MergePolicy) ([][]string, []string) {
+		files, _ := loadAllFiles()
+		return mergeLists(files, names, Options{
+			Strategy:    MergeByDir,
+			MergePolicy: p,
+			ExtraLists: map[string][]string{
+				"adjectives": {"zorblat", existing, "zorblat"},
+				"mine":       {"custom"},
+			},
+		})
+	}
+
+	lists, ids := build(PolicyReplace)
+	if !reflect.DeepEqual(ids, []string{"adjectives", "mine"}) {
+		t.Fatalf("replace ids %v", ids)
+	}
+	if !reflect.DeepEqual(lists[0], []string{"zorblat", existing}) {
+		t.Fatalf("replace got %v", lists[0])
+	}
+
+	lists, _ = build(PolicyAppend)
+	if len(lists[0]) != len(base[0])+2 || lists[0][len(lists[0])-1] != existing {
+		t.Fatalf("append got %d words want %d", len(lists[0]), len(base[0])+2)
+	}
+
+	lists, _ = build(PolicyAppendDedup)
+	if len(lists[0]) != len(base[0])+1 || lists[0][len(lists[0])-1] != "zorblat" {
+		t.Fatalf("append dedup got %d words want %d", len(lists[0]), len(base[0])+1)
+	}
+	if !reflect.DeepEqual(lists[1], []string{"custom"}) {
+		t.Fatalf("new id list got %v", lists[1])
+	}
+}
+
+/**
+ * TestPreserveGlobOrder checks lists follow include glob order for both dir and file strategies
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPreserveGlobOrder(t *testing.T) {
+	files := fileWords{
+		"adjectives/colors.txt": {"red"},
+		"nouns/animals.txt":     {"cat"},
+		"nouns/birds.txt":       {"owl"},
+		"verbs/moves.txt":       {"run"},
+	}
+	includes := []string{"verbs/*.txt", "nouns/birds.txt", "adjectives/*", "nouns/*"}
+	selected := orderByGlobs(globFilter(files, includes, nil), includes)
+
+	_, ids := mergeLists(files, selected, Options{Strategy: MergeByDir, PreserveGlobOrder: true})
+	if want := []string{"verbs", "nouns", "adjectives"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("by dir ids %v want %v", ids, want)
+	}
+
+	_, ids = mergeLists(files, selected, Options{Strategy: MergeByFile, PreserveGlobOrder: true})
+	want := []string{"verbs/moves.txt", "nouns/birds.txt", "adjectives/colors.txt", "nouns/animals.txt"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("by file ids %v want %v", ids, want)
+	}
+
+	// through New word positions follow the globs
+	g, err := New(Options{
+		IncludeGlobs:      []string{"verbs/*.txt", "adjectives/*.txt"},
+		Strategy:          MergeByDir,
+		PreserveGlobOrder: true,
+		Seed:              1,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	plain, _ := New(Options{IncludeGlobs: []string{"verbs/*.txt", "adjectives/*.txt"}, Strategy: MergeByDir, Seed: 1})
+	if len(g.lists) != 2 || len(g.lists[0]) != len(plain.lists[1]) {
+		t.Fatalf("expected verbs first got sizes %d %d", len(g.lists[0]), len(g.lists[1]))
+	}
+}
+
+/**
+ * TestMaxListsSeededSubset checks the cap holds and that seeds pick reproducible but different subsets
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMaxListsSeededSubset(t *testing.T) {
+	pickIDs := func(seed int64) string {
+		files, err := loadAllFiles()
+		if err != nil {
+			t.Fatalf("loadAllFiles: %v", err)
+		}
+		names := globFilter(files, []string{"**/*.txt"}, nil)
+		lists, ids := mergeLists(files, names, Options{Strategy: MergeByFile})
+		lists, ids = capLists(lists, ids, 5, seed, false)
+		if len(lists) > 5 || len(ids) != len(lists) {
+			t.Fatalf("cap exceeded got %d lists", len(lists))
+		}
+		return strings.Join(ids, ",")
+	}
+
+	if pickIDs(1) != pickIDs(1) {
+		t.Fatal("same seed picked different subsets")
+	}
+	if pickIDs(1) == pickIDs(2) && pickIDs(1) == pickIDs(3) {
+		t.Fatal("different seeds picked identical subsets")
+	}
+
+	g, err := New(Options{IncludeGlobs: []string{"**/*.txt"}, Strategy: MergeByFile, MaxLists: 3, Seed: 9})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(g.lists) > 3 {
+		t.Fatalf("generator kept %d lists want at most 3", len(g.lists))
+	}
+}
+
+/**
+ * TestOnEmptyListPolicies empties one list with a length filter and checks drop and error policies
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestOnEmptyListPolicies(t *testing.T) {
+	files := fileWords{
+		"a/short.txt": {"ox", "elk"},
+		"b/long.txt":  {"pelican", "flamingo"},
+	}
+	names := []string{"a/short.txt", "b/long.txt"}
+
+	lists, ids, dropped := buildLists(files, names, Options{Strategy: MergeByDir, MinLen: 5})
+	if len(lists) != 1 || ids[0] != "b" || !reflect.DeepEqual(dropped, []string{"a"}) {
+		t.Fatalf("drop policy got ids %v dropped %v", ids, dropped)
+	}
+
+	// cross dedup emptying a later list is reported too and never leaves an empty list behind
+	files = fileWords{"a/x.txt": {"foo"}, "b/y.txt": {"foo"}}
+	lists, _, dropped = buildLists(files, []string{"a/x.txt", "b/y.txt"}, Options{Strategy: MergeByDir, CrossDedup: true})
+	if len(lists) != 1 || !reflect.DeepEqual(dropped, []string{"b"}) {
+		t.Fatalf("cross dedup dropped %v lists %v", dropped, lists)
+	}
+
+	// through New the default drops quietly and the error policy names the list
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, MinLen: 14, MaxLen: 14, Seed: 1}
+	if _, err := New(opts); err != nil {
+		t.Fatalf("drop policy New: %v", err)
+	}
+	opts.OnEmptyList = EmptyListError
+	opts.MinLen, opts.MaxLen = 17, 0
+	_, err := New(opts)
+	if err == nil || !strings.Contains(err.Error(), "adjectives") {
+		t.Fatalf("error policy got %v", err)
+	}
+}
+
+/**
+ * TestCamelCasePhrases checks phrases fold into camel case tokens while single words stay as written
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCamelCasePhrases(t *testing.T) {
+	in := []string{"north star", "vega", "big  dipper", "Orion"}
+	got := normalizeAndFilter(in, Options{AllowPhrases: true, CamelCasePhrases: true})
+	want := []string{"NorthStar", "vega", "BigDipper", "Orion"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("camel case got %v want %v", got, want)
+	}
+
+	// lowercase runs first so the camel humps survive
+	in = []string{"NORTH STAR"}
+	got = normalizeAndFilter(in, Options{AllowPhrases: true, CamelCasePhrases: true, Lowercase: true})
+	if !reflect.DeepEqual(got, []string{"NorthStar"}) {
+		t.Fatalf("camel case after lowercase got %v", got)
+	}
+
+	// without AllowPhrases the phrase is kept as written and DropPhrases wins over folding
+	if got := normalizeAndFilter([]string{"north star"}, Options{CamelCasePhrases: true}); !reflect.DeepEqual(got, []string{"north star"}) {
+		t.Fatalf("expected phrase kept as written got %v", got)
+	}
+	if got := normalizeAndFilter([]string{"north star"}, Options{AllowPhrases: true, CamelCasePhrases: true, DropPhrases: true}); len(got) != 0 {
+		t.Fatalf("expected phrase dropped got %v", got)
+	}
+}
+
+/**
+ * TestScopedWordGlobs trims one file's vocabulary by glob under each strategy
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestScopedWordGlobs(t *testing.T) {
+	files := fileWords{
+		"nouns/fish.txt": {"catfish", "salmon", "goldfish", "trout"},
+		"nouns/cats.txt": {"tabby", "sphynx"},
+	}
+	names := []string{"nouns/cats.txt", "nouns/fish.txt"}
+
+	// file scoped exclude under by file
+	opts := Options{Exclude: map[string][]string{"nouns/fish.txt": {"*fish"}}}
+	lists, _ := mergeLists(files, names, opts)
+	if !reflect.DeepEqual(lists, [][]string{{"tabby", "sphynx"}, {"salmon", "trout"}}) {
+		t.Fatalf("by file exclude: %v", lists)
+	}
+	if len(files["nouns/fish.txt"]) != 4 {
+		t.Fatal("scoped filtering mutated the loaded file")
+	}
+
+	// file scoped include under by dir leaves the other file untouched
+	opts = Options{Strategy: MergeByDir, Include: map[string][]string{"nouns/fish.txt": {"*fish"}}}
+	lists, _ = mergeLists(files, names, opts)
+	if !reflect.DeepEqual(lists, [][]string{{"tabby", "sphynx", "catfish", "goldfish"}}) {
+		t.Fatalf("by dir include: %v", lists)
+	}
+
+	// dir scoped exclude applies to every file in the directory
+	opts = Options{Strategy: MergeSingle, Exclude: map[string][]string{"nouns": {"t*"}}}
+	lists, _ = mergeLists(files, names, opts)
+	if !reflect.DeepEqual(lists, [][]string{{"sphynx", "catfish", "salmon", "goldfish"}}) {
+		t.Fatalf("dir scoped exclude: %v", lists)
+	}
+}
+
+/**
+ * TestNewFromFilesStrategies mirrors TestMergeListsStrategies through the public constructor
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNewFromFilesStrategies(t *testing.T) {
+	files := map[string][]string{
+		"a/x.txt": {"foo", "bar"},
+		"a/y.txt": {"bar", "baz"},
+		"b/z.txt": {"foo"},
+	}
+
+	// by dir with cross dedup drops the emptied b bucket
+	g, err := NewFromFiles(files, Options{Strategy: MergeByDir, CrossDedup: true, Seed: 1})
+	if err != nil {
+		t.Fatalf("by dir: %v", err)
+	}
+	if len(g.lists) != 1 || !reflect.DeepEqual(g.lists[0], []string{"foo", "bar", "baz"}) {
+		t.Fatalf("by dir lists %v", g.lists)
+	}
+
+	// single flattened
+	g, err = NewFromFiles(files, Options{Strategy: MergeSingle, Lowercase: true, Seed: 1})
+	if err != nil || len(g.lists) != 1 {
+		t.Fatalf("single: %v %v", g, err)
+	}
+
+	// by file keeps one list per file and leaves the caller map alone
+	g, err = NewFromFiles(files, Options{Strategy: MergeByFile, Words: 3, Seed: 1})
+	if err != nil || len(g.lists) != 3 {
+		t.Fatalf("by file: %v", err)
+	}
+	if name := g.Generate(0); strings.Count(name, "_") != 2 {
+		t.Fatalf("unexpected name %q", name)
+	}
+	if !reflect.DeepEqual(files["a/y.txt"], []string{"bar", "baz"}) {
+		t.Fatal("NewFromFiles modified the input map")
+	}
+
+	// globs still select from the supplied files
+	if _, err := NewFromFiles(files, Options{IncludeGlobs: []string{"c/*.txt"}}); err == nil {
+		t.Fatal("expected error when globs match nothing")
+	}
+}
+
+/**
+ * TestFoldCaseForDedupKeepsCasing checks case variants collapse to the first spelling
+ * within a file and across lists with CrossDedup
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestFoldCaseForDedupKeepsCasing(t *testing.T) {
+	got := normalizeAndFilter([]string{"Brave", "brave", "BRAVE", "Otter"}, Options{FoldCaseForDedup: true})
+	if !reflect.DeepEqual(got, []string{"Brave", "Otter"}) {
+		t.Fatalf("in file fold: %v", got)
+	}
+
+	files := fileWords{"a/x.txt": {"Heron"}, "b/y.txt": {"heron", "Swift"}}
+	lists, _ := mergeLists(files, []string{"a/x.txt", "b/y.txt"}, Options{FoldCaseForDedup: true, CrossDedup: true})
+	if !reflect.DeepEqual(lists, [][]string{{"Heron"}, {"Swift"}}) {
+		t.Fatalf("cross fold: %v", lists)
+	}
+
+	// without the option casing variants stay distinct
+	if got := normalizeAndFilter([]string{"Brave", "brave"}, Options{}); len(got) != 2 {
+		t.Fatalf("expected variants kept got %v", got)
+	}
+}
+
+/**
+ * TestRequireVowelFiltersWords checks consonant only tokens never appear in output
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestRequireVowelFiltersWords(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"pwn", "brave", "rhythm", "3d", "zzz"}, "b/y.txt": {"otter", "crwth", "xkcd"}}
+	g, err := NewFromFiles(files, Options{Strategy: MergeByFile, Words: 2, RequireVowel: true, Seed: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		for _, w := range strings.Split(g.Generate(0), "_") {
+			if !hasVowel(w) {
+				t.Fatalf("word %q has no vowel", w)
+			}
+		}
+	}
+	if !reflect.DeepEqual(g.lists, [][]string{{"brave", "rhythm"}, {"otter"}}) {
+		t.Fatalf("unexpected lists %v", g.lists)
+	}
+}
+
+/**
+ * TestEmptyCorpusVersusEmptySelection separates a missing or empty lists root
+ * from globs that match none of the files that do exist
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEmptyCorpusVersusEmptySelection(t *testing.T) {
+	// root missing entirely
+	if _, err := newFromFS(fstest.MapFS{}, "lists", Options{}); !errors.Is(err, ErrEmptyCorpus) {
+		t.Fatalf("missing root: expected ErrEmptyCorpus got %v", err)
+	}
+
+	// root present but holding no txt files
+	noTxt := fstest.MapFS{"lists/README.md": {Data: []byte("hi")}}
+	if _, err := newFromFS(noTxt, "lists", Options{}); !errors.Is(err, ErrEmptyCorpus) {
+		t.Fatalf("no txt files: expected ErrEmptyCorpus got %v", err)
+	}
+
+	// files exist but the globs pick none of them
+	withTxt := fstest.MapFS{"lists/nouns/birds.txt": {Data: []byte("heron\n")}}
+	_, err := newFromFS(withTxt, "lists", Options{IncludeGlobs: []string{"verbs/*.txt"}})
+	if err == nil || errors.Is(err, ErrEmptyCorpus) || !strings.Contains(err.Error(), "no lists selected") {
+		t.Fatalf("selection: expected a selection error got %v", err)
+	}
+	if _, err := newFromFS(withTxt, "lists", Options{}); err != nil {
+		t.Fatalf("valid corpus: %v", err)
+	}
+}
+
+/**
+ * TestMinListSizeFoldsIntoMisc checks small lists join a trailing misc list or get dropped
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMinListSizeFoldsIntoMisc(t *testing.T) {
+	files := fileWords{
+		"a/x.txt": {"brave", "calm", "eager"},
+		"b/y.txt": {"otter"},
+		"c/z.txt": {"heron", "otter"},
+	}
+	names := []string{"a/x.txt", "b/y.txt", "c/z.txt"}
+
+	lists, ids := mergeLists(files, names, Options{Strategy: MergeByDir, MinListSize: 3})
+	if !reflect.DeepEqual(ids, []string{"a", "misc"}) || !reflect.DeepEqual(lists[1], []string{"otter", "heron"}) {
+		t.Fatalf("misc fold got ids %v lists %v", ids, lists)
+	}
+
+	lists, ids = mergeLists(files, names, Options{Strategy: MergeByDir, MinListSize: 3, OnSmallList: SmallListDrop})
+	if !reflect.DeepEqual(ids, []string{"a"}) || len(lists) != 1 {
+		t.Fatalf("drop got ids %v lists %v", ids, lists)
+	}
+
+	// a real list called misc keeps its id and the folded one takes the next free id
+	files["misc/w.txt"] = []string{"amber", "coral", "ivory"}
+	files["misc_2/v.txt"] = []string{"jade", "onyx", "ruby"}
+	names = append(names, "misc/w.txt", "misc_2/v.txt")
+	lists, ids = mergeLists(files, names, Options{Strategy: MergeByDir, MinListSize: 3, Exclude: map[string][]string{"misc_3": {"h*"}}})
+	if !reflect.DeepEqual(ids, []string{"a", "misc", "misc_2", "misc_3"}) || !reflect.DeepEqual(lists[3], []string{"otter"}) {
+		t.Fatalf("colliding misc got ids %v lists %v", ids, lists)
+	}
+}
+
+/**
+ * TestAvailableListsIncludesBundledIDs checks known dir and file ids are reported
+ * and that repeated generators leave the cached corpus untouched
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestAvailableListsIncludesBundledIDs(t *testing.T) {
+	ids := AvailableLists()
+	if !sort.StringsAreSorted(ids) {
+		t.Fatal("ids not sorted")
+	}
+	have := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		have[id] = true
+	}
+	for _, want := range []string{"adjectives", "nouns", "verbs", "adjectives/colors.txt", "nouns/birds.txt"} {
+		if !have[want] {
+			t.Fatalf("missing bundled id %q", want)
+		}
+	}
+
+	before, _ := loadAllFiles()
+	snapshot := append([]string(nil), before["nouns/birds.txt"]...)
+	if _, err := New(Options{IncludeGlobs: []string{"nouns/birds.txt"}, Strategy: MergeByFile, Upper: true, Lowercase: true, MaxLen: 5}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	after, _ := loadAllFiles()
+	if !reflect.DeepEqual(after["nouns/birds.txt"], snapshot) {
+		t.Fatal("building a generator modified the cached corpus")
+	}
+}
+
+/**
+ * TestRootBucketNameRenamesDotBucket checks top level files get the friendly bucket id
+ * and that it works as an Exclude key
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestRootBucketNameRenamesDotBucket(t *testing.T) {
+	files := fileWords{"top.txt": {"alpha", "beta"}, "nouns/x.txt": {"otter"}}
+	names := []string{"nouns/x.txt", "top.txt"}
+
+	_, ids := mergeLists(files, names, Options{Strategy: MergeByDir})
+	if !reflect.DeepEqual(ids, []string{".", "nouns"}) {
+		t.Fatalf("default ids %v", ids)
+	}
+
+	opts := Options{Strategy: MergeByDir, RootBucketName: "root", Exclude: map[string][]string{"root": {"b*"}}}
+	lists, ids := mergeLists(files, names, opts)
+	if !reflect.DeepEqual(ids, []string{"nouns", "root"}) || !reflect.DeepEqual(lists[1], []string{"alpha"}) {
+		t.Fatalf("renamed ids %v lists %v", ids, lists)
+	}
+}
+
+/**
+ * TestDedupScopes runs each scope over the three file fixture from TestMergeListsStrategies
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestDedupScopes(t *testing.T) {
+	files := fileWords{
+		"a/x.txt": {"foo", "bar"},
+		"a/y.txt": {"bar", "baz"},
+		"b/z.txt": {"foo"},
+	}
+	names := []string{"a/x.txt", "a/y.txt", "b/z.txt"}
+
+	cases := []struct {
+		scope DedupScope
+		want  [][]string
+	}{
+		{DedupWithinList, [][]string{{"foo", "bar", "baz"}, {"foo"}}},
+		{DedupNone, [][]string{{"foo", "bar", "bar", "baz"}, {"foo"}}},
+		{DedupAcrossLists, [][]string{{"foo", "bar", "baz"}}},
+	}
+	for _, c := range cases {
+		lists, _ := mergeLists(files, names, Options{Strategy: MergeByDir, DedupScope: c.scope})
+		if !reflect.DeepEqual(lists, c.want) {
+			t.Fatalf("scope %d: got %v want %v", c.scope, lists, c.want)
+		}
+	}
+
+	// DedupNone wins over the legacy CrossDedup flag
+	lists, _ := mergeLists(files, names, Options{Strategy: MergeByDir, DedupScope: DedupNone, CrossDedup: true})
+	if len(lists) != 2 {
+		t.Fatalf("DedupNone with CrossDedup got %v", lists)
+	}
+}
+
+/**
+ * TestEmptyIncludesPolicy checks both readings of empty includes with excludes present
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEmptyIncludesPolicy(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave"}, "b/y.txt": {"otter"}, "c/z.txt": {"heron"}}
+	excludes := []string{"b/*"}
+
+	g, err := NewFromFiles(files, Options{ExcludeGlobs: excludes, Strategy: MergeByFile, Seed: 1})
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	if !reflect.DeepEqual(g.ids, []string{"a/x.txt", "c/z.txt"}) {
+		t.Fatalf("all: ids %v", g.ids)
+	}
+
+	_, err = NewFromFiles(files, Options{ExcludeGlobs: excludes, EmptyIncludes: EmptyIncludesNone})
+	if err == nil || !strings.Contains(err.Error(), "no lists selected") {
+		t.Fatalf("none: expected a selection error got %v", err)
+	}
+
+	// an explicit glob still works under the strict reading
+	if _, err := NewFromFiles(files, Options{IncludeGlobs: []string{"c/*"}, EmptyIncludes: EmptyIncludesNone}); err != nil {
+		t.Fatalf("none with glob: %v", err)
+	}
+}
+
+/**
+ * TestSampleListCapBoundsLists checks no list exceeds the cap sampling repeats per seed
+ * and the plan and generation both use the sampled lists
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSampleListCapBoundsLists(t *testing.T) {
+	const limit = 25
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, SampleListCap: limit, Seed: 10}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i, l := range g.lists {
+		if len(l) != limit {
+			t.Fatalf("list %s has %d words want %d", g.ids[i], len(l), limit)
+		}
+	}
+	twin, _ := New(opts)
+	if !reflect.DeepEqual(g.lists, twin.lists) {
+		t.Fatal("sampling differs for the same seed")
+	}
+	for i := 0; i < 100; i++ {
+		for _, w := range strings.Split(g.Generate(0), "_") {
+			if !g.Contains(w) {
+				t.Fatalf("word %q not from the sampled lists", w)
+			}
+		}
+	}
+	rep, err := opts.Plan()
+	if err != nil || rep.Combinations != limit*limit {
+		t.Fatalf("plan combinations %d %v", rep.Combinations, err)
+	}
+}
+
+/**
+ * TestShuffleListsReproducible checks ShuffleLists permutes each list the same way
+ * for a seed so NameAt enumerates in a shuffled but repeatable order
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestShuffleListsReproducible(t *testing.T) {
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"}, Strategy: MergeByDir, Words: 2, Seed: 21}
+	plain, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	opts.ShuffleLists = true
+	a, _ := New(opts)
+	b, _ := New(opts)
+	if !reflect.DeepEqual(a.lists, b.lists) {
+		t.Fatal("same seed shuffled differently")
+	}
+	if reflect.DeepEqual(a.lists, plain.lists) {
+		t.Fatal("lists kept file order with ShuffleLists")
+	}
+	for i := range a.lists {
+		got := append([]string(nil), a.lists[i]...)
+		want := append([]string(nil), plain.lists[i]...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("list %s gained or lost words", a.ids[i])
+		}
+	}
+
+	for _, i := range []int64{0, 1, 7, 12345} {
+		x, err := a.NameAt(big.NewInt(i), 0)
+		if err != nil {
+			t.Fatalf("NameAt(%d): %v", i, err)
+		}
+		if y, _ := b.NameAt(big.NewInt(i), 0); x != y {
+			t.Fatalf("NameAt(%d) %q vs %q", i, x, y)
+		}
+	}
+	first, _ := plain.NameAt(big.NewInt(0), 0)
+	if want := plain.lists[0][0] + "_" + plain.lists[1][0]; first != want {
+		t.Fatalf("NameAt(0) = %q want %q", first, want)
+	}
+	second, _ := plain.NameAt(big.NewInt(1), 0)
+	if want := plain.lists[0][0] + "_" + plain.lists[1][1]; second != want {
+		t.Fatalf("NameAt(1) = %q want %q", second, want)
+	}
+	size := int64(len(plain.lists[0]) * len(plain.lists[1]))
+	if _, err := plain.NameAt(big.NewInt(size), 0); err == nil {
+		t.Fatal("NameAt past the end should fail")
+	}
+}
+
+/**
+ * TestStripCharsRemovesSeparators checks stripped characters vanish from built words
+ * and words made only of them are dropped
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestStripCharsRemovesSeparators(t *testing.T) {
+	files := map[string][]string{"food.txt": {"ice-cream", "hot_dog", "--", "pie"}}
+	g, err := NewFromFiles(files, Options{StripChars: "-_", Seed: 3})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	if want := []string{"icecream", "hotdog", "pie"}; !reflect.DeepEqual(g.lists[0], want) {
+		t.Fatalf("lists %v want %v", g.lists[0], want)
+	}
+
+	g, err = New(Options{IncludeGlobs: []string{"nouns/*.txt"}, StripChars: "-_ ", AllowPhrases: true, Seed: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, l := range g.lists {
+		for _, w := range l {
+			if strings.ContainsAny(w, "-_ ") {
+				t.Fatalf("word %q kept a stripped character", w)
+			}
+		}
+	}
+}
+
+/**
+ * TestNewFromTarSelectsEntries builds an in memory tar with two lists and a stray
+ * file and checks entry paths drive globbing like embedded file keys
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestNewFromTarSelectsEntries(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range []struct{ name, body string }{
+		{"./adjectives/mood.txt", "brave\ncalm\n# comment\n"},
+		{"nouns/animals.txt", "otter\nfox\n"},
+		{"README.md", "not a list\n"},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+
+	g, err := NewFromTar(bytes.NewReader(archive), Options{Strategy: MergeByDir, Words: 2, Seed: 3})
+	if err != nil {
+		t.Fatalf("NewFromTar: %v", err)
+	}
+	if want := []string{"adjectives", "nouns"}; !reflect.DeepEqual(g.ids, want) {
+		t.Fatalf("ids %v want %v", g.ids, want)
+	}
+	if !reflect.DeepEqual(g.lists[0], []string{"brave", "calm"}) {
+		t.Fatalf("adjectives %v", g.lists[0])
+	}
+
+	g, err = NewFromTar(bytes.NewReader(archive), Options{IncludeGlobs: []string{"nouns/*.txt"}, Words: 1, Seed: 3})
+	if err != nil {
+		t.Fatalf("NewFromTar with globs: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if w := g.Generate(0); w != "otter" && w != "fox" {
+			t.Fatalf("glob leaked %q", w)
+		}
+	}
+
+	if _, err := NewFromTar(bytes.NewReader(archive[:520]), Options{Seed: 3}); err == nil {
+		t.Fatal("truncated archive should fail")
+	}
+}
+
+/**
+ * TestListNamesSelect checks ListNames become include globs for whole
+ * directories at any depth or single files that union with IncludeGlobs and
+ * that a name matching nothing is an error
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestListNamesSelect(t *testing.T) {
+	files := fileWords{
+		"adjectives/age.txt":     {"old"},
+		"adjectives/colors.txt":  {"red"},
+		"names/cities/spain.txt": {"madrid"},
+		"names/people.txt":       {"ada"},
+		"nouns/fish.txt":         {"cod"},
+		"nouns/birds.txt":        {"owl"},
+	}
+	for _, c := range []struct {
+		names, includes []string
+		want            []string
+	}{
+		{[]string{"adjectives"}, nil, []string{"adjectives/age.txt", "adjectives/colors.txt"}},
+		{[]string{"names"}, nil, []string{"names/cities/spain.txt", "names/people.txt"}},
+		{[]string{"names/cities/"}, nil, []string{"names/cities/spain.txt"}},
+		{[]string{"nouns/fish"}, []string{"adjectives/age.txt"}, []string{"adjectives/age.txt", "nouns/fish.txt"}},
+	} {
+		opts := Options{ListNames: c.names, IncludeGlobs: c.includes}
+		opts.norm()
+		sel, err := selectLists(files, opts)
+		if err != nil {
+			t.Fatalf("selectLists(%v): %v", c.names, err)
+		}
+		if !reflect.DeepEqual(sel.selected, c.want) {
+			t.Fatalf("ListNames %v selected %v want %v", c.names, sel.selected, c.want)
+		}
+	}
+
+	if _, err := New(Options{ListNames: []string{"adjectives", "animalz"}}); err == nil || !strings.Contains(err.Error(), "animalz") {
+		t.Fatalf("expected error naming animalz got %v", err)
+	}
+	g, err := New(Options{ListNames: []string{"adjectives", "nouns"}, Strategy: MergeByDir})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if ids := g.Lists(); len(ids) != 2 || ids[0].ID != "adjectives" || ids[1].ID != "nouns" {
+		t.Fatalf("Lists = %+v", ids)
+	}
+}
+
+/**
+ * TestIncludeExcludeAfterNormalize checks the per list Include and Exclude maps
+ * match normalized words keyed by the merged list ids and run before CrossDedup
+ * so an excluded word never claims ownership away from a later list
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestIncludeExcludeAfterNormalize(t *testing.T) {
+	files := fileWords{
+		"adjectives/a.txt": {"Dead", "brave", "SICK", "otter"},
+		"nouns/n.txt":      {"otter", "fox", "owl"},
+	}
+	names := []string{"adjectives/a.txt", "nouns/n.txt"}
+	for _, c := range []struct {
+		opts Options
+		want [][]string
+	}{
+		// patterns see the lowercased words
+		{Options{Strategy: MergeByDir, Lowercase: true, Exclude: map[string][]string{"adjectives": {"dead", "sick"}}},
+			[][]string{{"brave", "otter"}, {"otter", "fox", "owl"}}},
+		// an excluded word leaves the later list its copy under CrossDedup
+		{Options{Strategy: MergeByDir, Lowercase: true, CrossDedup: true, Exclude: map[string][]string{"adjectives": {"otter"}}},
+			[][]string{{"dead", "brave", "sick"}, {"otter", "fox", "owl"}}},
+		{Options{Strategy: MergeByDir, Lowercase: true, CrossDedup: true},
+			[][]string{{"dead", "brave", "sick", "otter"}, {"fox", "owl"}}},
+		// Include restricts a list and CrossDedup still removes what earlier lists own
+		{Options{Strategy: MergeByDir, Lowercase: true, CrossDedup: true, Include: map[string][]string{"nouns": {"o*"}}},
+			[][]string{{"dead", "brave", "sick", "otter"}, {"owl"}}},
+		// file ids key MergeByFile lists
+		{Options{Lowercase: true, Include: map[string][]string{"adjectives/a.txt": {"brave"}}},
+			[][]string{{"brave"}, {"otter", "fox", "owl"}}},
+		// built ids key the MergeSingle list ExtraLists and the misc fold
+		{Options{Strategy: MergeSingle, Lowercase: true, Exclude: map[string][]string{"all": {"o*"}}},
+			[][]string{{"dead", "brave", "sick", "fox"}}},
+		{Options{Strategy: MergeByDir, Lowercase: true, ExtraLists: map[string][]string{"extra": {"Zeta", "alpha"}}, Include: map[string][]string{"extra": {"z*"}}},
+			[][]string{{"dead", "brave", "sick", "otter"}, {"otter", "fox", "owl"}, {"zeta"}}},
+		{Options{Strategy: MergeByDir, Lowercase: true, MinListSize: 5, Exclude: map[string][]string{"misc": {"o*"}}},
+			[][]string{{"dead", "brave", "sick", "fox"}}},
+	} {
+		lists, _ := mergeLists(files, names, c.opts)
+		if !reflect.DeepEqual(lists, c.want) {
+			t.Fatalf("mergeLists(%+v) = %v want %v", c.opts, lists, c.want)
+		}
+	}
+	if got := files["adjectives/a.txt"][0]; got != "Dead" {
+		t.Fatalf("loaded files were rewritten: %q", got)
+	}
+}
+
+/**
+ * TestExtraFSMergesWithEmbedded checks New adds ExtraFS files to the embedded
+ * corpus under their paths with the last filesystem winning on a shared path
+ * and globs and Plan treating every source alike
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestExtraFSMergesWithEmbedded(t *testing.T) {
+	first := fstest.MapFS{
+		"custom/mine.txt":   {Data: []byte("zorblat\nquixel\n")},
+		"nouns/birds.txt":   {Data: []byte("dodo\n")},
+		"custom/notes.md":   {Data: []byte("ignored\n")},
+		"custom/shared.txt": {Data: []byte("early\n")},
+	}
+	second := fstest.MapFS{"custom/shared.txt": {Data: []byte("late\n")}}
+
+	g, err := New(Options{ExtraFS: []fs.FS{first, second}, IncludeGlobs: []string{"custom/*.txt", "nouns/birds.txt"}, Strategy: MergeSingle, Words: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	got := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		got[g.Generate(0)] = true
+	}
+	want := map[string]bool{"zorblat": true, "quixel": true, "dodo": true, "late": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("generated %v want %v", got, want)
+	}
+
+	// embedded lists are still there next to the extra ones
+	rep, err := Options{ExtraFS: []fs.FS{first}, IncludeGlobs: []string{"custom/*.txt", "nouns/fish.txt"}}.Plan()
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if want := []string{"custom/mine.txt", "custom/shared.txt", "nouns/fish.txt"}; !reflect.DeepEqual(rep.Matched, want) {
+		t.Fatalf("Plan matched %v want %v", rep.Matched, want)
+	}
+
+	if _, err := New(Options{ExtraFS: []fs.FS{badFS{}}}); err == nil || !strings.Contains(err.Error(), "ExtraFS[0]") {
+		t.Fatalf("expected ExtraFS[0] error got %v", err)
+	}
+}
+
+// badFS is an fs.FS whose every Open fails
+type badFS struct{}
+
+func (badFS) Open(string) (fs.File, error) { return nil, fs.ErrPermission }
+
+/**
+ * TestOptionsListsAsFiles checks Options.Lists entries join the corpus as files
+ * keyed by list id go through normalization and merge under each strategy
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestOptionsListsAsFiles(t *testing.T) {
+	raw := map[string][]string{
+		"colors": {"Red", "GREEN", "blü", "x"},
+		"pets":   {"Dog", "cat", "ferret"},
+	}
+	opts := Options{Lists: raw, EmptyIncludes: EmptyIncludesNone, Lowercase: true, ASCIIOnly: true, MinLen: 2, Words: 2, Delimiter: '-', Seed: 4}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	want := []ListInfo{{ID: "colors", Words: 2}, {ID: "pets", Words: 3}}
+	if got := g.Lists(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lists = %+v want %+v", got, want)
+	}
+	for i := 0; i < 50; i++ {
+		name := g.Generate(0)
+		parts := strings.Split(name, "-")
+		if len(parts) != 2 || !slices.Contains([]string{"red", "green"}, parts[0]) || !slices.Contains([]string{"dog", "cat", "ferret"}, parts[1]) {
+			t.Fatalf("got %q", name)
+		}
+	}
+	if raw["colors"][0] != "Red" {
+		t.Fatalf("Options.Lists was rewritten: %v", raw["colors"])
+	}
+
+	// alongside embedded globs and flattened by MergeSingle
+	g, err = New(Options{Lists: map[string][]string{"mine/extra": {"zorblat"}}, IncludeGlobs: []string{"nouns/fish.txt"}, Strategy: MergeSingle})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if l := g.Lists(); len(l) != 1 || !g.Contains("zorblat") || !g.Contains("cod") {
+		t.Fatalf("MergeSingle lists %+v", l)
+	}
+	rep, err := Options{Lists: map[string][]string{"mine/extra": {"zorblat"}}, IncludeGlobs: []string{"nouns/fish.txt"}}.Plan()
+	if err != nil || !reflect.DeepEqual(rep.Matched, []string{"mine/extra", "nouns/fish.txt"}) {
+		t.Fatalf("Plan matched %v, %v", rep.Matched, err)
+	}
+}
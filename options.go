@@ -3,6 +3,9 @@ package namemachine
 import (
 	cryptoRand "crypto/rand"
 	"encoding/binary"
+	"io"
+	"io/fs"
+	"math/rand"
 	"time"
 )
 
@@ -17,13 +20,156 @@ const (
 	MergeSingle                      // all selected files become one list
 )
 
+/**
+ * EmptyListPolicy selects what New does when a selected list ends up with no words
+ */
+type EmptyListPolicy int
+
+const (
+	EmptyListDrop  EmptyListPolicy = iota // silently drop the list
+	EmptyListError                        // fail New naming the emptied list
+)
+
+/**
+ * SmallListPolicy selects what happens to lists below MinListSize
+ */
+type SmallListPolicy int
+
+const (
+	SmallListMisc SmallListPolicy = iota // fold small lists into one trailing misc list
+	SmallListDrop                        // drop small lists entirely
+)
+
+/**
+ * SuffixStrategy selects which suffix a name gets when both a slug and a
+ * counter are configured with only one configured that one is always used
+ */
+type SuffixStrategy int
+
+const (
+	SuffixBoth      SuffixStrategy = iota // slug then counter on every name
+	SuffixSlug                            // slug only the counter is ignored
+	SuffixCounter                         // counter only the slug is ignored
+	SuffixAlternate                       // counter on even generations slug on odd ones
+	SuffixRandom                          // a coin flip from the generator rng per name
+)
+
+/**
+ * DedupScope selects how far duplicate removal reaches
+ */
+type DedupScope int
+
+const (
+	DedupWithinList  DedupScope = iota // drop repeats inside each list the default
+	DedupNone                          // keep every repeat and ignore CrossDedup
+	DedupAcrossLists                   // also drop words seen in earlier lists like CrossDedup
+)
+
+/**
+ * EmptyIncludePolicy selects what an empty IncludeGlobs selects
+ */
+type EmptyIncludePolicy int
+
+const (
+	EmptyIncludesAll  EmptyIncludePolicy = iota // every file minus ExcludeGlobs the default
+	EmptyIncludesNone                           // nothing so New fails until a glob is given
+)
+
+/**
+ * PositionSampling selects how one word position draws from its list
+ */
+type PositionSampling int
+
+const (
+	SamplingDefault  PositionSampling = iota // follow LengthBias weighted when it is set
+	SamplingUniform                          // equal odds per word whatever LengthBias says
+	SamplingWeighted                         // LengthBias weights which must then be non zero
+)
+
+/**
+ * ReservedMatch picks how ReservedWords reject a name
+ */
+type ReservedMatch int
+
+const (
+	ReservedWhole   ReservedMatch = iota // reject a name equal to a reserved entry
+	ReservedAnyWord                      // also reject one with a reserved word between delimiters
+)
+
+/**
+ * SlugKind selects the preset alphabet slugs are drawn from
+ */
+type SlugKind int
+
+const (
+	SlugBase32   SlugKind = iota // lowercase rfc4648 base32 the default
+	SlugNoVowels                 // base32 without a e i o u so slugs rarely spell words
+)
+
+/**
+ * WordCase selects the casing each word gets as it is written
+ */
+type WordCase int
+
+const (
+	CaseNone  WordCase = iota // words as loaded the default
+	CaseTitle                 // first byte of each word uppercased
+	CaseUpper                 // every ascii letter of each word uppercased
+	CaseLower                 // every ascii letter of each word lowercased
+)
+
+/**
+ * SlugPosition selects where an extra slug from Options.Slugs is placed
+ */
+type SlugPosition int
+
+const (
+	SlugSuffix    SlugPosition = iota // after the words and the SlugLength slug the default
+	SlugPrefix                        // before the first word
+	SlugAfterWord                     // right after word AfterWord or the last word when out of range
+)
+
+/**
+ * SlugSpec describes one extra random segment such as the a3f in a3f-brave-otter
+ * Alphabet empty means the base32 slug alphabet
+ */
+type SlugSpec struct {
+	Length    int
+	Position  SlugPosition
+	AfterWord int // zero based word index used by SlugAfterWord
+	Alphabet  string
+}
+
+/**
+ * EntropyFailurePolicy selects what a build does when the entropy source fails
+ */
+type EntropyFailurePolicy int
+
+const (
+	EntropyFailFill  EntropyFailurePolicy = iota // pad the slug with the first alphabet symbol and use word zero
+	EntropyFailError                             // the same best effort name plus an ErrEntropy error
+)
+
+/**
+ * MergePolicy selects how a user supplied list combines with a built list of the same id
+ */
+type MergePolicy int
+
+const (
+	PolicyAppendDedup MergePolicy = iota // append user words skipping ones already present
+	PolicyAppend                         // append user words as is so repeats add weight
+	PolicyReplace                        // user words replace the built list
+)
+
 /**
  * Options controls selection normalization and generation behavior
  * fields are optional unless noted and sensible defaults are applied in norm
  */
 type Options struct {
 	// ListNames allows legacy selection by logical list name
-	// example adjectives animals
+	// example adjectives nouns/fish each name becomes include globs for that
+	// directory at any depth or that file and unions with IncludeGlobs
+	// a name that matches no file is an error from New
 	ListNames []string
 
 	// Word count behavior
@@ -33,12 +179,70 @@ type Options struct {
 	MinWords int
 	MaxWords int
 
+	// AllowedWordCounts picks the word count uniformly from this set instead of
+	// the MinWords..MaxWords range so {2,4} never yields three words
+	// repeating a count weights it Words still takes precedence
+	AllowedWordCounts []int
+
+	// LengthBias weights each word by its rune length raised to this power
+	// negative favors short punchy words positive long elaborate ones zero is uniform
+	LengthBias float64
+
+	// RecencyCooldown downweights a word for this many draws from its list after it
+	// is picked the odds climb back linearly so long streams cover the vocabulary
+	// more evenly without forbidding repeats zero disables
+	RecencyCooldown int
+
+	// NoAdjacentRepeat redraws a word equal to the word before it so lists that
+	// overlap without CrossDedup cannot give river_river after 8 redraws the
+	// repeat is kept so a list holding only that word still terminates
+	NoAdjacentRepeat bool
+
+	// FixedWords writes a literal at a zero based word position such as {1: "acme"}
+	// for brave-acme-otter the sampled words shift past it and keep their lists
+	// Words counts only sampled words so the literal makes the name longer
+	FixedWords map[int]string
+
+	// ReservedWords are names such as admin root or api that are never handed out
+	// a matching name is redrawn entries are compared ignoring ASCII case
+	// ReservedMatch widens the check from the whole name to any part of it
+	ReservedWords []string
+	ReservedMatch ReservedMatch
+
+	// PositionSampling sets the sampling mode of word position i independently
+	// so a curated short list keeps equal odds while another position is length weighted
+	// positions past the slice use SamplingDefault
+	PositionSampling []PositionSampling
+
 	// Delimiter placed between words and before slug when present
 	// default underscore (_)
 	Delimiter byte
 
+	// DelimiterString replaces Delimiter with a multi byte separator such as "::"
+	// or " - " between words and before the slug and counter when non empty
+	// WordDelimiters and NoSlugDelimiter stay single bytes and still win per boundary
+	DelimiterString string
+
+	// SlugDelimiter separates the slug and counter suffix from the words so
+	// '_' with '-' gives quiet_river-4821 zero falls back to Delimiter or DelimiterString
+	// extra Slugs keep Delimiter
+	SlugDelimiter byte
+
+	// WordDelimiters sets the byte between word i and i+1 cycling when shorter
+	// than needed so "-_" gives brave-otter_swift the slug still uses Delimiter or SlugDelimiter
+	WordDelimiters []byte
+
+	// NoDelimiter concatenates words directly so braveotter_swift the slug and
+	// counter still follow Delimiter and WordDelimiters NoSlugDelimiter are ignored
+	NoDelimiter bool
+
 	// Per list include and exclude filters
-	// keys are list identifiers values are words to include or exclude
+	// keys are a file such as nouns/fish.txt a directory such as nouns or a
+	// built list id such as all under MergeSingle an ExtraLists id or misc
+	// values are path.Match globs over that scope's words so {"*fish"} drops
+	// every word ending in fish a scope with Include globs keeps only matches
+	// globs see words after Lowercase and the other normalization and run
+	// before CrossDedup so {"adjectives": {"dead", "sick"}} just drops those
 	Include map[string][]string
 	Exclude map[string][]string
 
@@ -46,6 +250,144 @@ type Options struct {
 	// zero disables slug
 	SlugLength int
 
+	// MaxSlugLength is the largest SlugLength or Slugs length New accepts
+	// guarding against a config typo allocating huge names zero means 256
+	MaxSlugLength int
+
+	// Slugs adds extra random segments emitted in order at their positions
+	// each joined by Delimiter independent of SlugLength and SlugProbability
+	Slugs []SlugSpec
+
+	// SlugProbability gives each name the slug with this chance so some names
+	// go without one zero or one means every name when SlugLength is set
+	SlugProbability float64
+
+	// NoSlugDelimiter joins the words of names that carry no slug such as a space
+	// for human facing names while slugged ones keep Delimiter zero disables
+	NoSlugDelimiter byte
+
+	// SeededSlugs draws slug bytes from a chacha8 stream keyed by Seed
+	// instead of crypto rand so a seed reproduces its slugs while they still
+	// look cryptographically random without the key
+	SeededSlugs bool
+
+	// SlugKind picks the preset slug alphabet SlugNoVowels drops vowels so a slug
+	// is unlikely to read as a word sampling stays uniform over the smaller set
+	SlugKind SlugKind
+
+	// SlugAvoid removes these characters from the SlugKind slug alphabet
+	// for example "lo" to keep slugs easy to transcribe New errors if nothing is left
+	SlugAvoid string
+
+	// SlugGroup inserts SlugGroupSep after every SlugGroup slug chars
+	// so a3f9b2 reads a3f-9b2 zero disables SlugGroupSep defaults to dash
+	SlugGroup    int
+	SlugGroupSep byte
+
+	// RejectDegenerateSlugs redraws slugs made of one repeated symbol or only digits
+	// retries are bounded so a tiny alphabet cannot loop forever
+	RejectDegenerateSlugs bool
+
+	// SlugOnly emits just the slug and counter suffix with no words
+	// word count settings and per call overrides are ignored
+	SlugOnly bool
+
+	// CounterWidth appends a never repeating counter suffix of this many base32 chars
+	// the counter is run through a keyed feistel permutation so ids look random
+	// yet stay unique until all 32^width values are used zero disables
+	// CounterSeed keys the permutation and falls back to Seed when zero
+	CounterWidth int
+	CounterSeed  int64
+
+	// SuffixStrategy picks between the slug and counter when both are set
+	// the default SuffixBoth appends the slug then the counter
+	SuffixStrategy SuffixStrategy
+
+	// CounterStore persists the counter so a restarted process resumes past every
+	// value it may already have handed out needs a fixed CounterSeed or Seed
+	// CounterSaveEvery is how many values each Save reserves ahead default 1024
+	CounterStore     CounterStore
+	CounterSaveEvery int
+
+	// Casing of the final name
+	// Upper uppercases ascii letters of the whole name as it is written
+	// UpperRunes makes Upper rune aware so letters beyond ascii are uppercased too
+	// LowerSlug keeps the slug and counter suffix lowercase when Upper is set
+	Upper      bool
+	UpperRunes bool
+	LowerSlug  bool
+
+	// WordCase recases each word as it is written after loading and dedup so
+	// CaseTitle gives Brave_Otter the slug and counter keep their own casing
+	WordCase WordCase
+
+	// EnvVar is a preset for posix environment variable names like BRAVE_OTTER
+	// it forces Upper an underscore delimiter and ascii only words that start with a letter
+	// and caps the name at MaxTotalLen which defaults to 64 in this mode
+	// other delimiters PrefixWithListID SlugGroup and SoftHyphenate are cleared while
+	// SlugOnly prefix Slugs and FixedWords or slug alphabets outside [A-Za-z0-9] are errors
+	EnvVar bool
+
+	// PrefixWithListID writes each word as its source list id a colon and the word
+	// such as adjectives:brave_nouns:otter for debugging and analytics
+	PrefixWithListID bool
+
+	// SoftHyphenate breaks any word longer than this many runes with a hyphen every
+	// that many runes so incomprehensible reads incompre-hensible at 8 zero disables
+	SoftHyphenate int
+
+	// MaxTotalLen truncates names longer than this many bytes zero means no cap
+	// the words are cut so the slug and counter suffix survive whole and a
+	// trailing delimiter left by the cut is dropped a cap with no room for a word
+	// before the counter suffix is an error
+	MaxTotalLen int
+
+	// CryptoWords draws word indices from crypto rand so word choices are
+	// unpredictable and not reproducible from Seed slugs are unaffected
+	CryptoWords bool
+
+	// EntropySource supplies slug bytes and CryptoWords indices in place of crypto rand
+	// a short or failing reader triggers OnEntropyFailure for the rest of that name
+	EntropySource    io.Reader
+	OnEntropyFailure EntropyFailurePolicy
+
+	// AvoidCommonPrefix redraws a name whose first N characters match the previous
+	// name so listed names look distinct retries are bounded zero disables
+	AvoidCommonPrefix int
+
+	// OnCollision lets GenerateAvoiding mutate a taken candidate instead of redrawing
+	// such as appending -2 attempt counts from one across the whole call
+	// returning the name unchanged asks for a fresh draw attempts stay bounded
+	// the result is cut to MaxTotalLen and must still avoid ReservedWords
+	OnCollision func(name string, attempt int) string
+
+	// MinDistance redraws a name fewer than this many edits (Levenshtein over runes)
+	// away from the previous name so consecutive names are easy to tell apart zero disables
+	MinDistance int
+
+	// MaxGenerations caps how many names the generator hands out after which
+	// GenerateE reports ErrQuotaExceeded and other calls return empty names zero is unlimited
+	MaxGenerations int64
+
+	// StableRandom draws from the vendored splitmix64 stable source instead of the
+	// math/rand one so a Seed yields the same names on every Go release
+	// covers word picks word counts and load time shuffles and sampling
+	StableRandom bool
+
+	// Source replaces the seeded rng behind word picks word counts and the
+	// other per name draws so a pcg or xoshiro source or one shared by a test
+	// harness drives generation it wins over Seed and StableRandom there while
+	// Seed still keys load time shuffles and counters a source shared by several
+	// generators must be safe for their concurrent use nil keeps the seeded rng
+	Source rand.Source
+
+	// SingleThreaded skips the rng mutex for callers that use a generator from one goroutine
+	// faster but unsafe for concurrent use any concurrent call is a data race
+	// otherwise a call that finds the rng busy draws from a pooled shard seeded
+	// from Seed rather than waiting so a lone goroutine still sees the exact
+	// seeded sequence while concurrent output is not reproducible
+	SingleThreaded bool
+
 	// Seed for deterministic output in tests
 	// when zero a secure seed is drawn from crypto rand
 	Seed int64
@@ -53,27 +395,128 @@ type Options struct {
 	// Glob selection
 	// IncludeGlobs selects files to include
 	// ExcludeGlobs removes files from consideration
-	IncludeGlobs []string
-	ExcludeGlobs []string
+	// PreserveGlobOrder orders lists by the first include glob they match
+	// instead of lexically so word positions follow the globs as written
+	IncludeGlobs      []string
+	ExcludeGlobs      []string
+	PreserveGlobOrder bool
+
+	// EmptyIncludes decides whether no IncludeGlobs means every file or none
+	// EmptyIncludesAll keeps the historic include everything then subtract excludes
+	EmptyIncludes EmptyIncludePolicy
+
+	// Theme adds the curated include globs of a named theme such as ocean
+	// on top of IncludeGlobs see Themes for the known names
+	Theme string
 
 	// Merge strategy for building lists
 	Strategy MergeStrategy
 
+	// RootBucketName renames the dot bucket MergeByDir uses for top level files
+	// so the id reads like root in ids and Include or Exclude keys
+	RootBucketName string
+
+	// MaxLists keeps at most this many lists chosen by a shuffle seeded from Seed
+	// handy with MergeByFile over large corpora zero means no cap
+	MaxLists int
+
+	// SampleListCap down samples any list longer than this to exactly this many
+	// words seeded from Seed and in file order bounding memory for huge lists zero disables
+	SampleListCap int
+
+	// ShuffleLists reorders each list with a shuffle seeded from Seed at New
+	// so NameAt enumerates in a varied yet reproducible order
+	ShuffleLists bool
+
+	// Lists supplies raw words keyed by list id with no files involved each entry
+	// acts like a file at that path so it is always selected merges under the
+	// strategy and goes through the same normalization and filters pair it with
+	// EmptyIncludesNone to draw from these lists alone
+	Lists map[string][]string
+
+	// ExtraFS adds the txt files of caller filesystems walked from their root
+	// keyed by path like the embedded ones so globs and ids see one corpus
+	// on an identical path the last filesystem wins over earlier ones and the embedded lists
+	ExtraFS []fs.FS
+
+	// ExtraLists adds user supplied words keyed by list id after the strategy runs
+	// an id that matches a built list such as adjectives combines per MergePolicy
+	// unknown ids become new lists placed after the built ones in id order
+	ExtraLists  map[string][]string
+	MergePolicy MergePolicy
+
 	// Normalization and filters
 	// Lowercase converts tokens to lower case
 	// ASCIIOnly drops tokens with non ascii bytes
 	// MinLen and MaxLen keep tokens within bounds zero means no bound
 	// CrossDedup removes duplicate tokens across lists after they are built
-	Lowercase  bool
-	ASCIIOnly  bool
-	MinLen     int
-	MaxLen     int
-	CrossDedup bool
+	// DedupScope makes the reach explicit CrossDedup is the same as DedupAcrossLists
+	// AllowInFileDuplicates keeps repeats within a file so repetition weights a word
+	// RequireVowel drops words without an ascii vowel counting y for pronounceability
+	// FoldCaseForDedup compares words case insensitively when deduping but emits
+	// the first spelling seen so Brave and brave collapse into Brave
+	Lowercase             bool
+	ASCIIOnly             bool
+	MinLen                int
+	MaxLen                int
+	CrossDedup            bool
+	AllowInFileDuplicates bool
+	DedupScope            DedupScope
+	FoldCaseForDedup      bool
+	RequireVowel          bool
+
+	// StripChars removes each of these characters from every word before the
+	// other filters so "ice-cream" becomes icecream with "-" a word left empty is dropped
+	StripChars string
+
+	// Parsing
+	// TrimCutset lists characters trimmed from both ends of each line
+	// empty means whitespace and NoTrim disables trimming entirely
+	TrimCutset string
+	NoTrim     bool
+
+	// OnEmptyList decides what happens when filters empty a selected list
+	// dropping shifts word positions so strict callers can ask for an error
+	OnEmptyList EmptyListPolicy
+
+	// MinListSize treats lists with fewer words than this after filtering as too weak
+	// to own a word position OnSmallList folds them into a misc list or drops them
+	// the folded list is misc_2 misc_3 and so on when a built list is already misc
+	MinListSize int
+	OnSmallList SmallListPolicy
+
+	// Phrases
+	// tokens with internal spaces such as north star are kept as written by default
+	// DropPhrases removes them at load
+	// AllowPhrases turns on the phrase handling below
+	// PhraseJoin replaces those spaces with the delimiter when a phrase is emitted
+	// CamelCasePhrases folds a phrase into one token at load so north star becomes NorthStar
+	DropPhrases      bool
+	AllowPhrases     bool
+	PhraseJoin       bool
+	CamelCasePhrases bool
 }
 
+/**
+ * crossDedup reports whether words seen in earlier lists are removed
+ * @return bool true for CrossDedup or DedupAcrossLists unless DedupNone
+ */
+func (o Options) crossDedup() bool {
+	if o.DedupScope == DedupNone {
+		return false
+	}
+	return o.CrossDedup || o.DedupScope == DedupAcrossLists
+}
+
+/**
+ * envVarMaxLen is the default name cap applied by the EnvVar preset
+ */
+const envVarMaxLen = 64
+
 /**
  * norm applies default values to options in place
  * sets delimiter when empty and seeds the rng when seed is zero
+ * expands the EnvVar preset into the settings it implies
  * @param o *Options options to normalize
  * @return void
  */
@@ -81,6 +524,26 @@ func (o *Options) norm() {
 	if o.Delimiter == 0 {
 		o.Delimiter = '_'
 	}
+	if o.SlugGroup > 0 && o.SlugGroupSep == 0 {
+		o.SlugGroupSep = '-'
+	}
+	if o.EnvVar {
+		o.Upper = true
+		o.UpperRunes = false
+		o.LowerSlug = false
+		o.Delimiter = '_'
+		o.DelimiterString = ""
+		o.SlugDelimiter = 0
+		o.NoSlugDelimiter = 0
+		o.WordDelimiters = nil
+		o.PrefixWithListID = false
+		o.SlugGroup = 0
+		o.SoftHyphenate = 0
+		o.ASCIIOnly = true
+		if o.MaxTotalLen == 0 {
+			o.MaxTotalLen = envVarMaxLen
+		}
+	}
 	if o.Seed == 0 {
 		var seed [8]byte
 		if _, err := cryptoRand.Read(seed[:]); err != nil {
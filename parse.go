@@ -0,0 +1,328 @@
+package namemachine
+
+import "strings"
+
+/**
+ * Parse splits a generated name back into its words and slug
+ * uses the delimiter slug and counter settings from opts the same way New would
+ * the counter suffix when configured is checked for width and dropped
+ * ok is false when the name cannot have come from these settings
+ * words that contained the delimiter such as joined phrases split apart
+ * names from SuffixAlternate or SuffixRandom carry one suffix and are not split
+ * NoDelimiter words come back joined as a single element
+ * a name without the slug under SlugProbability is tried next with its words
+ * joined by NoSlugDelimiter when set and extra Slugs are checked for length and
+ * dropped like the counter SlugAfterWord specs need a single word delimiter
+ * @param name string a previously generated name
+ * @param opts Options settings the name was generated with
+ * @return []string words string slug and bool ok
+ */
+func Parse(name string, opts Options) (words []string, slug string, ok bool) {
+	delim := opts.Delimiter
+	if delim == 0 || opts.EnvVar {
+		delim = '_'
+	}
+	d := string(delim)
+	if opts.DelimiterString != "" && !opts.EnvVar {
+		d = opts.DelimiterString
+	}
+
+	p := nameLayout{
+		wordDelim:    d,
+		wordDelims:   string(opts.WordDelimiters),
+		slugDelim:    d,
+		slugLen:      groupedLen(opts.SlugLength, opts.SlugGroup),
+		counterWidth: opts.CounterWidth,
+		slugOnly:     opts.SlugOnly,
+		words:        opts.Words,
+		minWords:     opts.MinWords,
+		maxWords:     opts.MaxWords,
+		allowed:      opts.AllowedWordCounts,
+	}
+	if opts.SlugDelimiter != 0 && !opts.EnvVar {
+		p.slugDelim = string(opts.SlugDelimiter)
+	}
+	if opts.NoDelimiter {
+		p.wordDelim, p.wordDelims = "", ""
+	}
+	if opts.NoSlugDelimiter != 0 && len(opts.Slugs) == 0 && !opts.NoDelimiter && !opts.EnvVar {
+		p.bareDelim = string(opts.NoSlugDelimiter)
+	}
+	if opts.SlugProbability > 0 && opts.SlugProbability < 1 {
+		p.optSlug = true
+	}
+	if len(opts.Slugs) > 0 {
+		p.specDelim, p.specs = d, opts.Slugs
+	}
+	switch opts.SuffixStrategy {
+	case SuffixSlug:
+		if p.slugLen > 0 {
+			p.counterWidth = 0
+		}
+	case SuffixCounter:
+		if p.counterWidth > 0 {
+			p.slugLen = 0
+		}
+	}
+	return p.parse(name)
+}
+
+/**
+ * nameLayout describes how a name is assembled so it can be taken apart
+ * delimiters are strings so multi byte separators split the same way
+ */
+type nameLayout struct {
+	wordDelim    string
+	wordDelims   string // any of these bytes splits words when set
+	slugDelim    string
+	slugLen      int
+	counterWidth int
+	slugOnly     bool
+	optSlug      bool   // some names go without the slug
+	bareDelim    string // word delimiter for names without the slug empty means the usual ones
+	specDelim    string
+	specs        []SlugSpec
+
+	words    int
+	minWords int
+	maxWords int
+	allowed  []int
+}
+
+/**
+ * parse strips suffixes from the right then splits the words
+ * a layout with an optional slug tries the name again as one without it
+ * @param name string input name
+ * @return []string words string slug and bool ok
+ */
+func (p nameLayout) parse(name string) ([]string, string, bool) {
+	if p.slugOnly {
+		return p.parseSlugOnly(name)
+	}
+	if p.optSlug && p.slugLen > 0 {
+		if words, slug, ok := p.parseShape(name); ok {
+			return words, slug, true
+		}
+		p.slugLen = 0
+	}
+	return p.parseShape(name)
+}
+
+/**
+ * parseShape takes apart a name that carries every configured segment
+ * a layout without the slug joins its words with bareDelim when set
+ * @param name string input name
+ * @return []string words string slug and bool ok
+ */
+func (p nameLayout) parseShape(name string) ([]string, string, bool) {
+	rest := name
+
+	// counter is the last segment when configured
+	if p.counterWidth > 0 {
+		var ok bool
+		if rest, _, ok = cutSuffix(rest, p.slugDelim, p.counterWidth); !ok {
+			return nil, "", false
+		}
+	}
+
+	// suffix specs sit between the slug and the counter
+	var ok bool
+	if rest, ok = p.cutSpecs(rest, SlugSuffix); !ok {
+		return nil, "", false
+	}
+
+	// slug sits right before the counter or at the end
+	var slug string
+	if p.slugLen > 0 {
+		if rest, slug, ok = cutSuffix(rest, p.slugDelim, p.slugLen); !ok {
+			return nil, "", false
+		}
+	} else if p.bareDelim != "" {
+		p.wordDelim, p.wordDelims = p.bareDelim, ""
+	}
+	if rest, ok = p.cutSpecs(rest, SlugPrefix); !ok {
+		return nil, "", false
+	}
+
+	if rest == "" {
+		return nil, "", false
+	}
+	words := []string{rest}
+	switch {
+	case p.wordDelims != "":
+		words = splitAny(rest, p.wordDelims)
+	case p.wordDelim != "":
+		words = strings.Split(rest, p.wordDelim)
+	}
+	if words, ok = p.dropWordSpecs(words); !ok {
+		return nil, "", false
+	}
+	for _, w := range words {
+		if w == "" {
+			return nil, "", false
+		}
+	}
+
+	// word count must fit the configured shape when one is set
+	// concatenated words cannot be told apart so they come back as one
+	n := len(words)
+	switch {
+	case p.wordDelim == "" && p.wordDelims == "":
+		return words, slug, true
+	case p.words > 0 && n != p.words:
+		return nil, "", false
+	case p.words <= 0 && len(p.allowed) > 0 && !containsInt(p.allowed, n):
+		return nil, "", false
+	case p.words <= 0 && p.minWords > 0 && n < p.minWords:
+		return nil, "", false
+	case p.words <= 0 && p.maxWords > 0 && n > p.maxWords:
+		return nil, "", false
+	}
+	return words, slug, true
+}
+
+/**
+ * cutSpecs removes the extra slugs placed at pos from the end or the start of s
+ * suffix specs come off the end in reverse order and prefix specs off the start
+ * @param s string input
+ * @param pos SlugPosition SlugSuffix or SlugPrefix
+ * @return string remainder and bool ok when every spec was present
+ */
+func (p nameLayout) cutSpecs(s string, pos SlugPosition) (string, bool) {
+	if pos == SlugSuffix {
+		for i := len(p.specs) - 1; i >= 0; i-- {
+			if p.specs[i].Position != SlugSuffix {
+				continue
+			}
+			var ok bool
+			if s, _, ok = cutSuffix(s, p.specDelim, p.specs[i].Length); !ok {
+				return "", false
+			}
+		}
+		return s, true
+	}
+	for _, sp := range p.specs {
+		if sp.Position != SlugPrefix {
+			continue
+		}
+		if len(s) < sp.Length+len(p.specDelim) || s[sp.Length:sp.Length+len(p.specDelim)] != p.specDelim {
+			return "", false
+		}
+		s = s[sp.Length+len(p.specDelim):]
+	}
+	return s, true
+}
+
+/**
+ * dropWordSpecs removes the SlugAfterWord slugs from the split words
+ * each follows word AfterWord clamped to the last word in spec order
+ * @param parts []string fields split at the word delimiter
+ * @return []string the words alone and bool ok when every slug was found
+ */
+func (p nameLayout) dropWordSpecs(parts []string) ([]string, bool) {
+	after := 0
+	for _, sp := range p.specs {
+		if sp.Position == SlugAfterWord {
+			after++
+		}
+	}
+	if after == 0 {
+		return parts, true
+	}
+	if p.wordDelims != "" || p.wordDelim != p.specDelim {
+		return nil, false
+	}
+	last := len(parts) - after - 1
+	if last < 0 {
+		return nil, false
+	}
+	words := make([]string, 0, last+1)
+	j := 0
+	for i := 0; i <= last; i++ {
+		words = append(words, parts[j])
+		j++
+		for _, sp := range p.specs {
+			if sp.Position != SlugAfterWord || min(sp.AfterWord, last) != i {
+				continue
+			}
+			if len(parts[j]) != sp.Length {
+				return nil, false
+			}
+			j++
+		}
+	}
+	return words, true
+}
+
+/**
+ * parseSlugOnly checks a slug only name which has no words
+ * @param name string input name
+ * @return []string nil words string slug and bool ok
+ */
+func (p nameLayout) parseSlugOnly(name string) ([]string, string, bool) {
+	rest := name
+	if p.counterWidth > 0 {
+		if p.slugLen <= 0 {
+			return nil, "", len(name) == p.counterWidth
+		}
+		var ok bool
+		if rest, _, ok = cutSuffix(rest, p.slugDelim, p.counterWidth); !ok {
+			return nil, "", false
+		}
+	}
+	if len(rest) != p.slugLen {
+		return nil, "", false
+	}
+	return nil, rest, true
+}
+
+/**
+ * containsInt reports whether n is in xs
+ * @param xs []int values
+ * @param n int candidate
+ * @return bool true when present
+ */
+func containsInt(xs []int, n int) bool {
+	for _, x := range xs {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * cutSuffix removes a fixed width segment and its separator from the end of s
+ * @param s string input
+ * @param sep string separator expected before the segment
+ * @param width int segment length in bytes
+ * @return string remainder string segment and bool ok when present
+ */
+func cutSuffix(s, sep string, width int) (string, string, bool) {
+	if len(s) < width+len(sep) {
+		return "", "", false
+	}
+	cut := len(s) - width
+	if s[cut-len(sep):cut] != sep {
+		return "", "", false
+	}
+	return s[:cut-len(sep)], s[cut:], true
+}
+
+/**
+ * splitAny splits s at every byte found in seps keeping empty fields
+ * @param s string input
+ * @param seps string separator bytes
+ * @return []string fields between separators
+ */
+func splitAny(s, seps string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(seps, s[i]) >= 0 {
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
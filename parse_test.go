@@ -0,0 +1,125 @@
+package namemachine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestParseRoundTrip generates names under several layouts then parses them back
+ * and compares words and slug with the generated string
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestParseRoundTrip(t *testing.T) {
+	cases := []Options{
+		{Words: 2, Delimiter: '_'},
+		{Words: 3, Delimiter: '-', SlugLength: 6},
+		{MinWords: 1, MaxWords: 3, Delimiter: '.', SlugLength: 4, CounterWidth: 3},
+		{Words: 1, SlugLength: 8},
+	}
+	for ci, opts := range cases {
+		opts.IncludeGlobs = []string{"adjectives/*.txt", "nouns/*.txt"}
+		opts.Strategy = MergeByDir
+		opts.Seed = int64(ci + 1)
+		g, err := New(opts)
+		if err != nil {
+			t.Fatalf("case %d New: %v", ci, err)
+		}
+
+		d := string(g.delim)
+		for i := 0; i < 200; i++ {
+			name := g.Generate(0)
+			words, slug, ok := Parse(name, opts)
+			if !ok {
+				t.Fatalf("case %d: Parse(%q) not ok", ci, name)
+			}
+			if len(slug) != opts.SlugLength {
+				t.Fatalf("case %d: slug %q has wrong length for %q", ci, slug, name)
+			}
+
+			// rebuild and compare with the original minus the counter
+			rebuilt := strings.Join(words, d)
+			if slug != "" {
+				rebuilt += d + slug
+			}
+			want := name
+			if opts.CounterWidth > 0 {
+				want = name[:len(name)-opts.CounterWidth-1]
+			}
+			if rebuilt != want {
+				t.Fatalf("case %d: rebuilt %q want %q", ci, rebuilt, want)
+			}
+		}
+	}
+}
+
+/**
+ * TestParseLayoutMultiByteAndRejects covers multi byte delimiters a distinct slug delimiter
+ * and names that do not fit the layout
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestParseLayoutMultiByteAndRejects(t *testing.T) {
+	p := nameLayout{wordDelim: "::", slugDelim: "-", slugLen: 4, words: 2}
+	words, slug, ok := p.parse("brave::otter-k3qx")
+	if !ok || !reflect.DeepEqual(words, []string{"brave", "otter"}) || slug != "k3qx" {
+		t.Fatalf("multi byte parse got %v %q %v", words, slug, ok)
+	}
+
+	for _, bad := range []string{
+		"brave::otter",        // slug missing
+		"brave::otter::k3qx",  // slug delimiter wrong
+		"brave::::otter-k3qx", // empty word
+		"brave-k3qx",          // wrong word count
+		"-k3qx",               // no words at all
+	} {
+		if _, _, ok := p.parse(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+
+	if _, _, ok := Parse("a_b_c", Options{MinWords: 1, MaxWords: 2}); ok {
+		t.Fatal("expected range violation to be rejected")
+	}
+}
+
+/**
+ * TestParseDropsExtraSlugs checks Parse strips prefix suffix and after word slugs
+ * and the counter so the words and main slug come back as generated
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestParseDropsExtraSlugs(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave", "calm"}, "b/y.txt": {"otter", "heron"}}
+	opts := Options{
+		Strategy:     MergeByFile,
+		Words:        3,
+		SlugLength:   4,
+		CounterWidth: 3,
+		Slugs: []SlugSpec{
+			{Length: 3, Position: SlugPrefix},
+			{Length: 2, Position: SlugAfterWord, AfterWord: 0},
+			{Length: 2, Position: SlugAfterWord, AfterWord: 9},
+			{Length: 6},
+		},
+		Seed: 2,
+	}
+	g, err := NewFromFiles(files, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		name := g.Generate(0)
+		parts := strings.Split(name, "_")
+		want := []string{parts[1], parts[3], parts[4]}
+		words, slug, ok := Parse(name, opts)
+		if !ok || !reflect.DeepEqual(words, want) || slug != parts[6] {
+			t.Fatalf("Parse(%q) = %q %q %v want %q %q", name, words, slug, ok, want, parts[6])
+		}
+	}
+	if _, _, ok := Parse("abc_brave_otter_calm_xy_abcd_abcdef_001", opts); ok {
+		t.Fatal("Parse accepted a name missing an after word slug")
+	}
+}
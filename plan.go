@@ -0,0 +1,289 @@
+package namemachine
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+/**
+ * selection is the outcome of picking and merging files for a generator
+ */
+type selection struct {
+	candidates []string   // files matched by the include globs before excludes
+	selected   []string   // files left after excludes in list order
+	lists      [][]string // merged lists after filters and MaxLists
+	ids        []string   // ids aligned with lists
+}
+
+/**
+ * selectLists runs glob selection merging and capping shared by New and Plan
+ * @param files fileWords loaded files
+ * @param opts Options normalized configuration
+ * @return selection and error when nothing usable is selected
+ */
+func selectLists(files fileWords, opts Options) (selection, error) {
+	var sel selection
+
+	// select files using include and exclude globs widened by any theme and ListNames
+	includes, err := themeGlobs(opts.Theme, opts.IncludeGlobs)
+	if err != nil {
+		return sel, err
+	}
+	if len(opts.ListNames) > 0 {
+		named, err := listNameGlobs(files, opts.ListNames)
+		if err != nil {
+			return sel, err
+		}
+		includes = append(includes[:len(includes):len(includes)], named...)
+	}
+	if len(includes) > 0 || opts.EmptyIncludes == EmptyIncludesAll {
+		sel.candidates = globFilter(files, includes, nil)
+		sel.selected = globFilter(files, includes, opts.ExcludeGlobs)
+	}
+	if len(opts.Lists) > 0 {
+		sel.candidates = withListKeys(sel.candidates, opts.Lists)
+		sel.selected = withListKeys(sel.selected, opts.Lists)
+	}
+	if opts.PreserveGlobOrder {
+		sel.selected = orderByGlobs(sel.selected, includes)
+	}
+
+	// merge selected files into lists based on strategy
+	lists, ids, dropped := buildLists(files, sel.selected, opts)
+	if opts.OnEmptyList == EmptyListError && len(dropped) > 0 {
+		return sel, fmt.Errorf("list %q is empty after filtering", dropped[0])
+	}
+	sel.lists, sel.ids = capLists(lists, ids, opts.MaxLists, opts.Seed, opts.StableRandom)
+	sel.lists = sampleLists(sel.lists, opts.SampleListCap, opts.Seed, opts.StableRandom)
+	if opts.ShuffleLists {
+		sel.lists = shuffleLists(sel.lists, opts.Seed, opts.StableRandom)
+	}
+
+	// require at least one list to proceed
+	if len(sel.selected) == 0 {
+		return sel, fmt.Errorf("no lists selected (IncludeGlobs/ExcludeGlobs matched zero files)")
+	}
+	if len(sel.lists) == 0 {
+		return sel, fmt.Errorf("no lists left (every selected list is empty after filtering)")
+	}
+	return sel, nil
+}
+
+/**
+ * PlanList is one list a generator would draw from
+ */
+type PlanList struct {
+	ID    string
+	Words int
+}
+
+/**
+ * PlanReport describes what New would select without building a generator
+ * Combinations counts distinct word sequences over every possible word count
+ * ignoring slugs and counters and saturates at math.MaxUint64
+ */
+type PlanReport struct {
+	Matched      []string // files selected in list order
+	Excluded     []string // files matched by includes but removed by excludes
+	Lists        []PlanList
+	Combinations uint64
+}
+
+/**
+ * Plan reports the files lists and combination count these options select
+ * against the embedded corpus without seeding an rng or building a generator
+ * useful for debugging globs before calling New
+ * @return PlanReport selection report and error as New would return it
+ */
+func (o Options) Plan() (PlanReport, error) {
+	o.norm()
+	files, err := loadAllFiles()
+	if err != nil {
+		return PlanReport{}, err
+	}
+	if o.TrimCutset != "" || o.NoTrim {
+		if files, err = loadFiles(listsFS, "lists", o); err != nil {
+			return PlanReport{}, err
+		}
+	}
+	if err := addExtraFS(files, o); err != nil {
+		return PlanReport{}, err
+	}
+	addLists(files, o)
+	sel, err := selectLists(files, o)
+	if err != nil {
+		return PlanReport{}, err
+	}
+
+	rep := PlanReport{Matched: sel.selected}
+	kept := make(map[string]struct{}, len(sel.selected))
+	for _, n := range sel.selected {
+		kept[n] = struct{}{}
+	}
+	for _, n := range sel.candidates {
+		if _, ok := kept[n]; !ok {
+			rep.Excluded = append(rep.Excluded, n)
+		}
+	}
+	sizes := make([]int, len(sel.lists))
+	for i, l := range sel.lists {
+		rep.Lists = append(rep.Lists, PlanList{ID: sel.ids[i], Words: len(l)})
+		sizes[i] = len(l)
+	}
+	rep.Combinations = combinations(sizes, planWordCounts(o))
+	return rep, nil
+}
+
+/**
+ * planWordCounts lists every word count the options can produce
+ * mirrors wordCount and randWordCount precedence
+ * @param o Options normalized configuration
+ * @return []int possible word counts without repeats
+ */
+func planWordCounts(o Options) []int {
+	switch {
+	case o.SlugOnly:
+		return nil
+	case o.Words > 0:
+		return []int{o.Words}
+	case len(o.AllowedWordCounts) > 0:
+		var out []int
+		for _, n := range o.AllowedWordCounts {
+			if !containsInt(out, n) {
+				out = append(out, n)
+			}
+		}
+		return out
+	case o.MinWords <= 0 && o.MaxWords <= 0:
+		return []int{2}
+	}
+	lo, hi := o.MinWords, o.MaxWords
+	if lo <= 0 {
+		lo = 1
+	}
+	if hi < lo {
+		hi = lo
+	}
+	out := make([]int, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		out = append(out, n)
+	}
+	return out
+}
+
+/**
+ * combinations sums the word sequences for each count where position i draws
+ * from list i mod len(sizes) the result saturates instead of overflowing
+ * @param sizes []int words per list
+ * @param counts []int word counts to total
+ * @return uint64 number of distinct sequences
+ */
+func combinations(sizes []int, counts []int) uint64 {
+	if len(sizes) == 0 {
+		return 0
+	}
+	var total uint64
+	for _, n := range counts {
+		c := uint64(1)
+		for i := 0; i < n; i++ {
+			hi, lo := bits.Mul64(c, uint64(sizes[i%len(sizes)]))
+			if hi != 0 {
+				return math.MaxUint64
+			}
+			c = lo
+		}
+		var carry uint64
+		if total, carry = bits.Add64(total, c, 0); carry != 0 {
+			return math.MaxUint64
+		}
+	}
+	return total
+}
+
+/**
+ * CollisionProbability estimates the chance that n names drawn with config
+ * contain at least one repeat using the birthday approximation 1 - exp(-pairs/space)
+ * each word count gets its own space from the Plan list sizes times the slug space
+ * when every name carries the slug a counter on every name makes repeats impossible
+ * until its space runs out and LengthBias is treated as uniform
+ * @param config Options configuration to estimate
+ * @param n int number of names expected
+ * @return float64 probability in 0..1 or NaN when the options select nothing
+ */
+func CollisionProbability(config Options, n int) float64 {
+	rep, err := config.Plan()
+	if err != nil || len(rep.Lists) == 0 {
+		return math.NaN()
+	}
+	o := config
+	o.norm()
+	if n < 2 {
+		return 0
+	}
+	if o.CounterWidth > 0 && (o.SuffixStrategy == SuffixBoth || o.SuffixStrategy == SuffixCounter) {
+		if float64(n) <= math.Pow(32, float64(o.CounterWidth)) {
+			return 0
+		}
+		return 1
+	}
+
+	// log of the suffix space every name shares
+	suffix := 0.0
+	if o.SlugLength > 0 && o.slugOnEvery() {
+		alphabet, err := slugAlphabet(o.SlugKind, o.SlugAvoid)
+		if err != nil {
+			return math.NaN()
+		}
+		suffix += float64(o.SlugLength) * math.Log(float64(len(alphabet)))
+	}
+	for _, sp := range o.Slugs {
+		size := len(base32)
+		if sp.Alphabet != "" {
+			size = len(sp.Alphabet)
+		}
+		suffix += float64(sp.Length) * math.Log(float64(size))
+	}
+
+	// names of different word counts never collide so sum the expected pairs per count
+	counts, weights := collisionCounts(o)
+	pairs := float64(n) * float64(n-1) / 2
+	lambda := 0.0
+	for i, k := range counts {
+		space := suffix
+		for j := 0; j < k; j++ {
+			space += math.Log(float64(rep.Lists[j%len(rep.Lists)].Words))
+		}
+		lambda += math.Exp(math.Log(pairs*weights[i]*weights[i]) - space)
+	}
+	return -math.Expm1(-lambda)
+}
+
+/**
+ * collisionCounts lists the word counts with the share of names each one gets
+ * AllowedWordCounts repeats weight a count the way the generator draws it
+ * @param o Options normalized configuration
+ * @return []int counts and []float64 their probabilities
+ */
+func collisionCounts(o Options) ([]int, []float64) {
+	if o.SlugOnly {
+		return []int{0}, []float64{1}
+	}
+	counts := planWordCounts(o)
+	weights := make([]float64, len(counts))
+	if o.Words <= 0 && len(o.AllowedWordCounts) > 0 {
+		for i, k := range counts {
+			for _, a := range o.AllowedWordCounts {
+				if a == k {
+					weights[i]++
+				}
+			}
+			weights[i] /= float64(len(o.AllowedWordCounts))
+		}
+		return counts, weights
+	}
+	for i := range weights {
+		weights[i] = 1 / float64(len(counts))
+	}
+	return counts, weights
+}
@@ -0,0 +1,106 @@
+package namemachine
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+/**
+ * TestPlanMatchesGenerator checks a plan reports the same lists New builds
+ * along with excluded files and the combination count
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestPlanMatchesGenerator(t *testing.T) {
+	opts := Options{
+		IncludeGlobs: []string{"nouns/b*.txt"},
+		ExcludeGlobs: []string{"nouns/buildings.txt"},
+		Strategy:     MergeByFile,
+		Words:        2,
+		Seed:         3,
+	}
+	rep, err := opts.Plan()
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if !reflect.DeepEqual(rep.Matched, []string{"nouns/birds.txt"}) || !reflect.DeepEqual(rep.Excluded, []string{"nouns/buildings.txt"}) {
+		t.Fatalf("matched %v excluded %v", rep.Matched, rep.Excluded)
+	}
+
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(rep.Lists) != len(g.lists) {
+		t.Fatalf("plan has %d lists generator %d", len(rep.Lists), len(g.lists))
+	}
+	for i, l := range rep.Lists {
+		if l.Words != len(g.lists[i]) {
+			t.Fatalf("list %s: plan %d words generator %d", l.ID, l.Words, len(g.lists[i]))
+		}
+	}
+	n := uint64(len(g.lists[0]))
+	if rep.Combinations != n*n {
+		t.Fatalf("combinations %d want %d", rep.Combinations, n*n)
+	}
+
+	if _, err := (Options{IncludeGlobs: []string{"nope/*.txt"}}).Plan(); err == nil {
+		t.Fatal("expected selection error from Plan")
+	}
+	if got := combinations([]int{1 << 20}, []int{4}); got != math.MaxUint64 {
+		t.Fatalf("expected saturation got %d", got)
+	}
+}
+
+/**
+ * TestCollisionProbabilityMatchesSimulation compares the birthday estimate with
+ * a Monte Carlo run over a small corpus with and without a slug
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestCollisionProbabilityMatchesSimulation(t *testing.T) {
+	cases := []struct {
+		opts Options
+		n    int
+	}{
+		{Options{IncludeGlobs: []string{"adjectives/complexity.txt", "adjectives/speed.txt"}, Words: 2}, 20},
+		{Options{IncludeGlobs: []string{"adjectives/complexity.txt"}, Words: 1, SlugLength: 1}, 25},
+	}
+	for _, c := range cases {
+		est := CollisionProbability(c.opts, c.n)
+		if est <= 0.1 || est >= 0.9 {
+			t.Fatalf("estimate %v too extreme to compare", est)
+		}
+
+		const trials = 600
+		hits := 0
+		for trial := 0; trial < trials; trial++ {
+			opts := c.opts
+			opts.Seed = int64(trial + 1)
+			g, err := New(opts)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			seen := map[string]bool{}
+			for i := 0; i < c.n; i++ {
+				name := g.Generate(0)
+				if seen[name] {
+					hits++
+					break
+				}
+				seen[name] = true
+			}
+		}
+		if sim := float64(hits) / trials; math.Abs(sim-est) > 0.07 {
+			t.Fatalf("estimate %.3f simulation %.3f for %+v", est, sim, c.opts)
+		}
+	}
+
+	if p := CollisionProbability(Options{IncludeGlobs: []string{"nouns/*.txt"}, CounterWidth: 4}, 1000); p != 0 {
+		t.Fatalf("counter on every name got %v want 0", p)
+	}
+	if p := CollisionProbability(Options{IncludeGlobs: []string{"missing/*.txt"}}, 10); !math.IsNaN(p) {
+		t.Fatalf("empty selection got %v want NaN", p)
+	}
+}
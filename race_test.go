@@ -0,0 +1,7 @@
+//go:build race
+
+package namemachine
+
+// raceEnabled skips allocation assertions since the race runtime moves
+// buffers handed to crypto rand onto the heap
+const raceEnabled = true
@@ -0,0 +1,279 @@
+package namemachine
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+/**
+ * Reserve hands out n distinct names and holds them so later Reserve and
+ * Generate calls on this generator skip them until they are Released
+ * either all n names are reserved or none are and each counts against MaxGenerations
+ * @param n int number of names
+ * @param nWords int optional override for number of words
+ * @return []string reserved names and ErrExhausted when n free names cannot be found
+ * or ErrQuotaExceeded when fewer than n generations remain
+ */
+func (g *Generator) Reserve(n, nWords int) ([]string, error) {
+	if len(g.lists) == 0 {
+		return nil, ErrNoLists
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	if !g.takeQuotaN(n) {
+		return nil, ErrQuotaExceeded
+	}
+
+	g.heldMu.Lock()
+	defer g.heldMu.Unlock()
+	if g.held == nil {
+		g.held = make(map[string]struct{})
+	}
+
+	out := make([]string, 0, n)
+	fresh := make(map[string]struct{}, n)
+	buf := make([]byte, 0, 64)
+	for tries := 0; len(out) < n; tries++ {
+		if tries >= 16*n+64 {
+			g.returnQuota(n)
+			return nil, ErrExhausted
+		}
+		var err error
+		if buf, err = g.build(buf[:0], g.wordCount(nWords)); err != nil {
+			g.returnQuota(n)
+			return nil, err
+		}
+		if _, ok := g.held[string(buf)]; ok || g.isReserved(buf) {
+			continue
+		}
+		if _, ok := fresh[string(buf)]; ok {
+			continue
+		}
+		name := string(buf)
+		fresh[name] = struct{}{}
+		out = append(out, name)
+	}
+
+	for _, name := range out {
+		g.held[name] = struct{}{}
+	}
+	atomic.StoreInt64(&g.heldN, int64(len(g.held)))
+	return out, nil
+}
+
+/**
+ * Release returns reserved names so they may be generated again
+ * names that are not reserved are ignored
+ * @param names ...string names from Reserve
+ * @return void
+ */
+func (g *Generator) Release(names ...string) {
+	g.heldMu.Lock()
+	for _, name := range names {
+		delete(g.held, name)
+	}
+	atomic.StoreInt64(&g.heldN, int64(len(g.held)))
+	g.heldMu.Unlock()
+}
+
+/**
+ * uniqueTries caps the redraws GenerateUnique spends on one name
+ * small spaces get eight draws per possible name instead
+ */
+const uniqueTries = 1 << 16
+
+/**
+ * GenerateUnique generates a name this generator has not returned from
+ * GenerateUnique before since the last Reset whatever word count either call used
+ * every name is kept in memory so the cost grows by roughly the name length
+ * plus map overhead per call uniqueness is per generator instance only and is
+ * not persisted use UniqueStream with a CounterStore to survive restarts
+ * each call takes one MaxGenerations slot however often it redraws
+ * safe for concurrent use
+ * TotalCombinations summed over the distinct word counts is only an upper bound
+ * since NoDelimiter joins and MaxTotalLen truncation can make names collide so
+ * near that bound the bounded redraws are what report exhaustion and with a
+ * counter the bound is skipped since the counter space is left out of it
+ * @param nWords int optional override for number of words
+ * @return string name and bool false once the bound is reached redraws ran out
+ * on a nearly full space or the quota is spent
+ */
+func (g *Generator) GenerateUnique(nWords int) (string, bool) {
+	if len(g.lists) == 0 {
+		return "", false
+	}
+	counts := []int{nWords}
+	if nWords <= 0 {
+		counts = g.wordCounts()
+	}
+	var total *big.Int
+	if g.counterWidth == 0 {
+		total = new(big.Int)
+		for i, c := range counts {
+			if !containsInt(counts[:i], c) {
+				total.Add(total, g.TotalCombinations(c))
+			}
+		}
+	}
+
+	g.uniqueMu.Lock()
+	defer g.uniqueMu.Unlock()
+	tries := uniqueTries
+	if total != nil {
+		used := int64(0)
+		for i, c := range counts {
+			if !containsInt(counts[:i], c) {
+				used += int64(g.emittedN[c])
+			}
+		}
+		if total.Cmp(big.NewInt(used)) <= 0 {
+			return "", false
+		}
+		if total.IsInt64() && total.Int64() < uniqueTries/8 {
+			tries = int(total.Int64()) * 8
+		}
+	}
+	if !g.takeQuota() {
+		return "", false
+	}
+
+	seen := func(name []byte) bool {
+		_, ok := g.emitted[string(name)]
+		return ok
+	}
+	count := 0
+	buf := make([]byte, 0, 64)
+	for ; tries > 0; tries -= maxRedraws {
+		var err error
+		buf, err = g.buildChecked(buf[:0], func() int {
+			count = g.wordCount(nWords)
+			return count
+		}, seen)
+		if errors.Is(err, ErrExhausted) {
+			continue
+		}
+		if err != nil {
+			return "", false
+		}
+		if g.emitted == nil {
+			g.emitted = make(map[string]struct{})
+			g.emittedN = make(map[int]int)
+		}
+		name := string(buf)
+		g.emitted[name] = struct{}{}
+		g.emittedN[count]++
+		return name, true
+	}
+	return "", false
+}
+
+/**
+ * Reset forgets every name GenerateUnique has handed out freeing that memory
+ * @return void
+ */
+func (g *Generator) Reset() {
+	g.uniqueMu.Lock()
+	g.emitted, g.emittedN = nil, nil
+	g.uniqueMu.Unlock()
+}
+
+/**
+ * isHeld reports whether name is currently reserved
+ * @param name []byte candidate name
+ * @return bool true while the name is held by Reserve
+ */
+func (g *Generator) isHeld(name []byte) bool {
+	if atomic.LoadInt64(&g.heldN) == 0 {
+		return false
+	}
+	g.heldMu.Lock()
+	_, ok := g.held[string(name)]
+	g.heldMu.Unlock()
+	return ok
+}
+
+/**
+ * isReserved reports whether name matches ReservedWords
+ * under ReservedAnyWord every run between delimiter bytes is checked as well
+ * so a slug or list id spelling a reserved word is caught too
+ * @param name []byte candidate name
+ * @return bool true when the name must be redrawn
+ */
+func (g *Generator) isReserved(name []byte) bool {
+	if g.reservedWords == nil {
+		return false
+	}
+	if g.reservedHas(name) {
+		return true
+	}
+	if g.reservedMatch != ReservedAnyWord {
+		return false
+	}
+	start := 0
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && !g.isBoundary(name[i]) {
+			continue
+		}
+		if g.reservedHas(name[start:i]) {
+			return true
+		}
+		start = i + 1
+	}
+	return false
+}
+
+/**
+ * reservedHas looks tok up in the reserved set ignoring ASCII case
+ * @param tok []byte whole name or one part of it
+ * @return bool true when tok is reserved
+ */
+func (g *Generator) reservedHas(tok []byte) bool {
+	if len(tok) == 0 || len(tok) > g.reservedMax {
+		return false
+	}
+	var buf [64]byte
+	key := append(buf[:0], tok...)
+	lowerASCII(key)
+	_, ok := g.reservedWords[string(key)]
+	return ok
+}
+
+/**
+ * isBoundary reports whether c can separate the parts of a generated name
+ * @param c byte name byte
+ * @return bool true for the delimiters in use and the list id separator
+ */
+func (g *Generator) isBoundary(c byte) bool {
+	if bytes.IndexByte(g.delim, c) >= 0 || bytes.IndexByte(g.slugDelim, c) >= 0 || c == listIDSep || g.bareDelim != 0 && c == g.bareDelim {
+		return true
+	}
+	for _, d := range g.wordDelims {
+		if c == d {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * reservedSet folds ReservedWords into the lookup set isReserved uses
+ * @param words []string reserved entries
+ * @return map[string]struct{} lowercased entries nil when there are none and int longest entry
+ */
+func reservedSet(words []string) (map[string]struct{}, int) {
+	if len(words) == 0 {
+		return nil, 0
+	}
+	set := make(map[string]struct{}, len(words))
+	longest := 0
+	for _, w := range words {
+		w = strings.ToLower(w)
+		set[w] = struct{}{}
+		longest = max(longest, len(w))
+	}
+	return set, longest
+}
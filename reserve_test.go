@@ -0,0 +1,257 @@
+package namemachine
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestReserveHoldsNames checks two Reserve calls never overlap Generate skips held names
+ * and Reserve reports exhaustion without holding a partial block
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestReserveHoldsNames(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave", "calm", "eager", "fond"}}
+	g, err := NewFromFiles(files, Options{Words: 1, Seed: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	first, err := g.Reserve(2, 0)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	second, err := g.Reserve(2, 0)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	held := map[string]bool{}
+	for _, n := range append(first, second...) {
+		if held[n] {
+			t.Fatalf("name %q reserved twice in %v %v", n, first, second)
+		}
+		held[n] = true
+	}
+
+	// every name is held so Generate and Reserve both run dry
+	if _, err := g.GenerateE(0); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected ErrExhausted from GenerateE got %v", err)
+	}
+	if _, err := g.Reserve(1, 0); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected ErrExhausted from Reserve got %v", err)
+	}
+
+	// releasing one makes it the only name Generate can return
+	g.Release(first[0])
+	for i := 0; i < 20; i++ {
+		if name := g.Generate(0); name != first[0] {
+			t.Fatalf("expected released %q got %q", first[0], name)
+		}
+	}
+	if _, err := g.Reserve(2, 0); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("partial reserve should fail got %v", err)
+	}
+	if got, err := g.Reserve(1, 0); err != nil || got[0] != first[0] {
+		t.Fatalf("failed reserve left names held: %v %v", got, err)
+	}
+}
+
+/**
+ * TestReservedNamesStayHeldOnEveryPath checks a held name never leaks from the range
+ * and detailed paths and that reservations count against MaxGenerations
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestReservedNamesStayHeldOnEveryPath(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"a", "b"}}
+	g, err := NewFromFiles(files, Options{Words: 1, MaxGenerations: 5, Seed: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	held, err := g.Reserve(1, 1)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if _, err := g.Reserve(5, 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Reserve past the quota got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if name := g.GenerateRange(1, 1); name == held[0] || name == "" {
+			t.Fatalf("GenerateRange returned %q with %q held", name, held[0])
+		}
+		if name := g.GenerateDetailed(0).Name; name == held[0] || name == "" {
+			t.Fatalf("GenerateDetailed returned %q with %q held", name, held[0])
+		}
+	}
+	if name := g.Generate(0); name != "" {
+		t.Fatalf("quota of 5 allowed a sixth name %q", name)
+	}
+
+	g, _ = NewFromFiles(files, Options{Words: 1, Seed: 3})
+	held, _ = g.Reserve(1, 1)
+	for i := 0; i < 400; i++ {
+		if g.GenerateRange(1, 1) == held[0] || g.GenerateDetailed(0).Name == held[0] {
+			t.Fatalf("draw %d leaked held %q", i, held[0])
+		}
+	}
+
+	// a UniqueStream skips the held name like GenerateIndices does
+	s, err := g.UniqueStream(0, nil)
+	if err != nil {
+		t.Fatalf("UniqueStream: %v", err)
+	}
+	if name, err := s.Next(); err != nil || name == held[0] {
+		t.Fatalf("Next = %q %v with %q held", name, err, held[0])
+	}
+	if name, err := s.Next(); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("Next returned held %q want ErrExhausted got %v", name, err)
+	}
+}
+
+/**
+ * TestReservedWordsRedrawn checks ReservedWords never come out as a whole name
+ * and under ReservedAnyWord never as one of its words either
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestReservedWordsRedrawn(t *testing.T) {
+	files := map[string][]string{"a.txt": {"admin", "brave", "root"}, "b.txt": {"api", "otter"}}
+	g, err := NewFromFiles(files, Options{Words: 1, ReservedWords: []string{"ADMIN", "root"}, Seed: 2})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		if name := g.Generate(0); name == "admin" || name == "root" {
+			t.Fatalf("reserved name %q handed out", name)
+		}
+	}
+
+	// whole name mode still lets a reserved entry appear among other words
+	g, err = NewFromFiles(files, Options{Words: 2, ReservedWords: []string{"api"}, Seed: 2})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	seen := false
+	for i := 0; i < 200; i++ {
+		seen = seen || strings.HasSuffix(g.Generate(0), "_api")
+	}
+	if !seen {
+		t.Fatalf("ReservedWhole rejected names that merely contain api")
+	}
+
+	g, err = NewFromFiles(files, Options{Words: 2, WordDelimiters: []byte("-"), ReservedWords: []string{"api", "root"}, ReservedMatch: ReservedAnyWord, Seed: 2})
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		name := g.Generate(0)
+		for _, w := range strings.Split(name, "-") {
+			if w == "api" || w == "root" {
+				t.Fatalf("reserved word %q in %q", w, name)
+			}
+		}
+	}
+	if names, err := g.Reserve(2, 0); err != nil || slices.ContainsFunc(names, func(n string) bool { return strings.Contains(n, "api") }) {
+		t.Fatalf("Reserve = %v, %v", names, err)
+	}
+
+	if _, err := NewFromFiles(files, Options{FixedWords: map[int]string{0: "Root"}, ReservedWords: []string{"root"}, ReservedMatch: ReservedAnyWord}); err == nil {
+		t.Fatalf("expected error for a reserved FixedWords literal")
+	}
+}
+
+/**
+ * TestGenerateUniqueExhaustsAndResets checks every name comes once then false until Reset
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGenerateUniqueExhaustsAndResets(t *testing.T) {
+	g, err := NewFromFiles(map[string][]string{"a": {"red", "blue", "green"}, "b": {"fox", "owl"}}, Options{
+		Words: 2,
+		Seed:  5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := int(g.TotalCombinations(2).Int64())
+	if total != 6 {
+		t.Fatalf("TotalCombinations = %d want 6", total)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < total; i++ {
+		name, ok := g.GenerateUnique(0)
+		if !ok || seen[name] {
+			t.Fatalf("draw %d = %q %v after %v", i, name, ok, seen)
+		}
+		seen[name] = true
+	}
+	if name, ok := g.GenerateUnique(2); ok {
+		t.Fatalf("exhausted space returned %q", name)
+	}
+
+	g.Reset()
+	if _, ok := g.GenerateUnique(0); !ok {
+		t.Fatal("GenerateUnique after Reset failed")
+	}
+
+	// repeated AllowedWordCounts entries count once so a full space stops without drawing
+	g, err = NewFromFiles(map[string][]string{"a": {"x", "y"}}, Options{AllowedWordCounts: []int{1, 1}, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.GenerateUnique(0)
+	g.GenerateUnique(0)
+	pos := g.Position()
+	if name, ok := g.GenerateUnique(0); ok || g.Position() != pos {
+		t.Fatalf("third name %q %v drew %d names", name, ok, g.Position()-pos)
+	}
+
+	// NoDelimiter makes a+bc and ab+c collide so only three of the four counted names exist
+	// each call takes one quota slot however many duplicates it redraws
+	g, err = NewFromFiles(map[string][]string{"p/1": {"a", "ab"}, "q/2": {"c", "bc"}}, Options{Strategy: MergeByDir, NoDelimiter: true, Words: 2, MaxGenerations: 10, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := 0
+	for _, ok := g.GenerateUnique(0); ok; _, ok = g.GenerateUnique(0) {
+		got++
+	}
+	if got != 3 {
+		t.Fatalf("NoDelimiter unique names %d want 3", got)
+	}
+	if g.issued != 4 {
+		t.Fatalf("four calls used %d generations want 4", g.issued)
+	}
+
+	// uniqueness spans word counts so GenerateUnique(1) cannot repeat a name from GenerateUnique(0)
+	g, err = NewFromFiles(map[string][]string{"a": {"x", "y"}}, Options{MinWords: 1, MaxWords: 2, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen = map[string]bool{}
+	for name, ok := g.GenerateUnique(0); ok; name, ok = g.GenerateUnique(0) {
+		if seen[name] {
+			t.Fatalf("GenerateUnique(0) repeated %q", name)
+		}
+		seen[name] = true
+	}
+	if len(seen) != 6 {
+		t.Fatalf("one and two word names %d want 6", len(seen))
+	}
+	if name, ok := g.GenerateUnique(1); ok {
+		t.Fatalf("GenerateUnique(1) repeated %q from GenerateUnique(0)", name)
+	}
+
+	// a counter leaves the bound so the space is not reported full after the word space
+	g, err = NewFromFiles(map[string][]string{"a": {"x", "y"}}, Options{Words: 1, CounterWidth: 2, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if name, ok := g.GenerateUnique(0); !ok {
+			t.Fatalf("counter name %d %q reported exhausted", i, name)
+		}
+	}
+}
@@ -4,23 +4,6 @@ import (
 	"testing"
 )
 
-/**
- * isAlnumASCII reports whether the input contains only ascii digits or letters
- * digits zero to nine and letters a to z or A to Z
- * @param s string input token
- * @return bool true when token is ascii alphanumeric only
- */
-func isAlnumASCII(s string) bool {
-	// scan byte by byte and reject on first non alnum
-	for i := 0; i < len(s); i++ {
-		b := s[i]
-		if !((b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')) {
-			return false
-		}
-	}
-	return true
-}
-
 /**
  * TestAllWords_AreAlnumOnly enforces that every embedded word is ascii alphanumeric
  * catches spaces punctuation emoji and any other non alnum content
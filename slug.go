@@ -2,6 +2,14 @@ package namemachine
 
 import (
 	cryptoRand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"strings"
+	"sync"
 )
 
 /**
@@ -11,6 +19,9 @@ import (
  */
 var base32 = []byte("abcdefghijklmnopqrstuvwxyz234567")
 
+// maxSlugRedraws bounds retries for RejectDegenerateSlugs the last draw is kept
+const maxSlugRedraws = 8
+
 /**
  * randomSlugInto appends a base32 slug of length n into dst
  * uses crypto strong randomness and falls back to a safe filler on error
@@ -20,33 +31,389 @@ var base32 = []byte("abcdefghijklmnopqrstuvwxyz234567")
  * @return []byte the destination buffer with slug appended
  */
 func randomSlugInto(dst []byte, n int) []byte {
-	if n <= 0 {
-		return dst
+	return appendSlug(dst, n, base32)
+}
+
+/**
+ * appendSlug appends a slug of length n drawn from the given alphabet
+ * same crypto source and fallback as randomSlugInto
+ * @param dst []byte destination buffer provided by caller
+ * @param n int desired slug length
+ * @param alphabet []byte symbols to draw from must be non empty
+ * @return []byte the destination buffer with slug appended
+ */
+func appendSlug(dst []byte, n int, alphabet []byte) []byte {
+	var e entropyBuf
+	return e.appendSlug(dst, n, alphabet)
+}
+
+/**
+ * entropyBuf hands out crypto bytes from one buffered read
+ * a build shares one buffer across word indices and the slug so a typical
+ * name costs a single read the zero value is empty and reads crypto rand
+ */
+type entropyBuf struct {
+	buf   [64]byte
+	off   int        // next unread byte
+	n     int        // bytes filled by the last read
+	reads int        // reads issued handy for benchmarks
+	src   io.Reader  // entropy source nil means crypto rand
+	err   error      // first read failure bytes read before it are still used
+	rng   *rand.Rand // rng from acquire for this name nil means the generator rng
+}
+
+/**
+ * next returns the next entropy byte refilling the buffer when it runs dry
+ * a short read hands out the bytes it got and fails on the following refill
+ * @return byte value and bool false when the source failed
+ */
+func (e *entropyBuf) next() (byte, bool) {
+	if e.off == e.n {
+		if e.err != nil {
+			return 0, false
+		}
+		e.reads++
+		e.n, e.err = readEntropy(e.src, e.buf[:])
+		e.off = 0
+		if e.n == 0 {
+			if e.err == nil {
+				e.err = io.ErrNoProgress
+			}
+			return 0, false
+		}
+	}
+	b := e.buf[e.off]
+	e.off++
+	return b, true
+}
+
+/**
+ * uint64 assembles a little endian word from the next eight bytes
+ * @return uint64 value and bool false when the source failed
+ */
+func (e *entropyBuf) uint64() (uint64, bool) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		b, ok := e.next()
+		if !ok {
+			return 0, false
+		}
+		v |= uint64(b) << (8 * i)
 	}
+	return v, true
+}
 
-	// read randomness in a small fixed buffer for speed and simplicity
-	var buf [16]byte
-	i := 0
+/**
+ * discard drops buffered bytes so the next draw starts from a fresh read
+ * @return void
+ */
+func (e *entropyBuf) discard() {
+	e.off = e.n
+}
 
-	for i < n {
-		// try to fill the buffer with crypto randomness
-		if _, err := cryptoRand.Read(buf[:]); err != nil {
-			// on failure fill the remainder with the first alphabet symbol
-			for i < n {
-				dst = append(dst, base32[0])
-				i++
+/**
+ * appendSlug appends n symbols mapping each byte to the alphabet by modulo
+ * bytes at or above byteLimit are skipped so every symbol stays equally likely
+ * once the source fails the remainder is filled with the first alphabet symbol
+ * @param dst []byte destination buffer
+ * @param n int desired slug length
+ * @param alphabet []byte symbols to draw from must be non empty
+ * @return []byte the destination buffer with slug appended
+ */
+func (e *entropyBuf) appendSlug(dst []byte, n int, alphabet []byte) []byte {
+	limit := byteLimit(len(alphabet))
+	for i := 0; i < n; {
+		b, ok := e.next()
+		if !ok {
+			for ; i < n; i++ {
+				dst = append(dst, alphabet[0])
 			}
 			break
 		}
+		if int(b) >= limit {
+			continue
+		}
+		dst = append(dst, alphabet[int(b)%len(alphabet)])
+		i++
+	}
+	return dst
+}
+
+/**
+ * byteLimit is the largest multiple of n not above 256
+ * bytes below it map onto n symbols without modulo bias
+ * @param n int alphabet size
+ * @return int exclusive bound for accepted bytes
+ */
+func byteLimit(n int) int {
+	return 256 - 256%n
+}
+
+/**
+ * intn returns a uniform index in [0,n) from crypto bytes
+ * rejection sampling removes modulo bias and a failed read falls back to zero
+ * @param n int upper bound must be positive
+ * @return int index
+ */
+func (e *entropyBuf) intn(n int) int {
+	limit := math.MaxUint64 - math.MaxUint64%uint64(n)
+	for {
+		v, ok := e.uint64()
+		if !ok {
+			return 0
+		}
+		if v < limit {
+			return int(v % uint64(n))
+		}
+	}
+}
+
+/**
+ * float64 returns a uniform float in [0,1) from crypto bytes
+ * @return float64 value using the top 53 bits of a crypto word
+ */
+func (e *entropyBuf) float64() float64 {
+	v, _ := e.uint64()
+	return float64(v>>11) / (1 << 53)
+}
+
+/**
+ * entropyPool holds heap scratch for custom entropy sources sized like entropyBuf.buf
+ * the source sees pooled memory so the caller's buffer stays on its stack
+ */
+var entropyPool = sync.Pool{
+	New: func() any { return new([64]byte) },
+}
+
+/**
+ * readEntropy fills b from src or crypto rand when src is nil
+ * a custom source reads into pooled scratch so b never escapes and no read allocates
+ * @param src io.Reader entropy source or nil
+ * @param b []byte destination at most 64 bytes
+ * @return int bytes filled and error from the source
+ */
+func readEntropy(src io.Reader, b []byte) (int, error) {
+	if src != nil {
+		tmp := entropyPool.Get().(*[64]byte)
+		n, err := io.ReadFull(src, tmp[:len(b)])
+		copy(b, tmp[:n])
+		entropyPool.Put(tmp)
+		return n, err
+	}
+	_, err := cryptoRand.Read(b)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+/**
+ * newSlugStream keys a chacha8 stream from a seed for reproducible slugs
+ * the 64 bit seed is stretched to a 256 bit key with splitmix
+ * @param seed int64 generator seed
+ * @return *randv2.ChaCha8 keyed stream
+ */
+func newSlugStream(seed int64) *randv2.ChaCha8 {
+	var key [32]byte
+	s := uint64(seed)
+	for i := 0; i < len(key); i += 8 {
+		s += 0x9E3779B97F4A7C15
+		binary.LittleEndian.PutUint64(key[i:], mix64(s))
+	}
+	return randv2.NewChaCha8(key)
+}
 
-		// map each random byte to an alphabet index using modulo
-		for _, b := range buf {
-			dst = append(dst, base32[int(b)%len(base32)])
-			i++
-			if i >= n {
-				break
+/**
+ * appendSlugStream appends a slug of length n drawing bytes from a chacha8 stream
+ * maps bytes onto the alphabet the same way as the crypto path rejection included
+ * the caller serializes access to the stream
+ * @param dst []byte destination buffer
+ * @param n int desired slug length
+ * @param alphabet []byte symbols to draw from must be non empty
+ * @param c *randv2.ChaCha8 keyed stream
+ * @return []byte the destination buffer with slug appended
+ */
+func appendSlugStream(dst []byte, n int, alphabet []byte, c *randv2.ChaCha8) []byte {
+	limit := byteLimit(len(alphabet))
+	for n > 0 {
+		v := c.Uint64()
+		for k := 0; k < 8 && n > 0; k++ {
+			if b := int(byte(v)); b < limit {
+				dst = append(dst, alphabet[b%len(alphabet)])
+				n--
 			}
+			v >>= 8
 		}
 	}
 	return dst
 }
+
+/**
+ * noVowels is base32 without a e i o u so slugs are unlikely to spell words
+ */
+var noVowels = []byte("bcdfghjklmnpqrstvwxyz234567")
+
+/**
+ * slugAlphabet returns the alphabet for kind minus the avoided characters
+ * @param kind SlugKind preset alphabet
+ * @param avoid string characters to remove
+ * @return []byte effective alphabet and error when nothing is left
+ */
+func slugAlphabet(kind SlugKind, avoid string) ([]byte, error) {
+	base := base32
+	switch kind {
+	case SlugBase32:
+	case SlugNoVowels:
+		base = noVowels
+	default:
+		return nil, fmt.Errorf("unknown SlugKind %d", kind)
+	}
+	if avoid == "" {
+		return base, nil
+	}
+	out := make([]byte, 0, len(base))
+	for _, c := range base {
+		if !strings.ContainsRune(avoid, rune(c)) {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("SlugAvoid %q removes every slug character", avoid)
+	}
+	return out, nil
+}
+
+/**
+ * groupedLen is the slug length once a separator follows every group chars
+ * @param n int slug chars
+ * @param group int group size zero means no grouping
+ * @return int total bytes including separators
+ */
+func groupedLen(n, group int) int {
+	if group <= 0 || n <= 0 {
+		return n
+	}
+	return n + (n-1)/group
+}
+
+/**
+ * groupSlug spreads the slug at dst[start:] into groups split by sep
+ * works in place from the back so no scratch buffer is needed
+ * @param dst []byte buffer whose tail is the slug
+ * @param start int index where the slug begins
+ * @param group int group size
+ * @param sep byte separator between groups
+ * @return []byte the buffer with the grouped slug
+ */
+func groupSlug(dst []byte, start, group int, sep byte) []byte {
+	n := len(dst) - start
+	extra := groupedLen(n, group) - n
+	if extra == 0 {
+		return dst
+	}
+	for i := 0; i < extra; i++ {
+		dst = append(dst, 0)
+	}
+	w := len(dst) - 1
+	for i := n - 1; i >= 0; i-- {
+		dst[w] = dst[start+i]
+		w--
+		if i > 0 && i%group == 0 {
+			dst[w] = sep
+			w--
+		}
+	}
+	return dst
+}
+
+/**
+ * isDegenerateSlug reports slugs that look broken such as 222222 or aaaaaa
+ * a single symbol repeated or nothing but digits single chars never count
+ * @param s []byte slug bytes
+ * @return bool true when the slug should be redrawn
+ */
+func isDegenerateSlug(s []byte) bool {
+	if len(s) < 2 {
+		return false
+	}
+	same, digits := true, true
+	for _, c := range s {
+		if c != s[0] {
+			same = false
+		}
+		if c < '0' || c > '9' {
+			digits = false
+		}
+	}
+	return same || digits
+}
+
+/**
+ * slugSpec is a validated SlugSpec ready for the hot path
+ */
+type slugSpec struct {
+	n        int
+	pos      SlugPosition
+	after    int
+	alphabet []byte
+}
+
+/**
+ * newSlugSpecs validates extra slug specs and resolves their alphabets
+ * @param specs []SlugSpec options as given
+ * @param delimLen int bytes in the delimiter before each slug
+ * @return []slugSpec resolved specs nil when none and int bytes they add with delimiters and error
+ */
+func newSlugSpecs(specs []SlugSpec, delimLen int) ([]slugSpec, int, error) {
+	if len(specs) == 0 {
+		return nil, 0, nil
+	}
+	out := make([]slugSpec, 0, len(specs))
+	size := 0
+	for i, sp := range specs {
+		if sp.Length <= 0 {
+			return nil, 0, fmt.Errorf("Slugs[%d] length %d must be greater than zero", i, sp.Length)
+		}
+		if sp.Position < SlugSuffix || sp.Position > SlugAfterWord {
+			return nil, 0, fmt.Errorf("Slugs[%d] has unknown position %d", i, sp.Position)
+		}
+		if sp.AfterWord < 0 {
+			return nil, 0, fmt.Errorf("Slugs[%d] AfterWord %d must not be negative", i, sp.AfterWord)
+		}
+		alphabet := base32
+		if sp.Alphabet != "" {
+			if err := checkAlphabet(sp.Alphabet); err != nil {
+				return nil, 0, fmt.Errorf("Slugs[%d] %w", i, err)
+			}
+			alphabet = []byte(sp.Alphabet)
+		}
+		out = append(out, slugSpec{n: sp.Length, pos: sp.Position, after: sp.AfterWord, alphabet: alphabet})
+		size += delimLen + sp.Length
+	}
+	return out, size, nil
+}
+
+/**
+ * checkAlphabet rejects custom slug alphabets that cannot be sampled uniformly
+ * symbols are single ascii bytes so a slug stays valid utf8 and each appears once
+ * so none is favoured which also keeps the size within the 256 values of a byte
+ * @param alphabet string symbols as given
+ * @return error describing the first problem nil when usable
+ */
+func checkAlphabet(alphabet string) error {
+	if alphabet == "" || len(alphabet) > 256 {
+		return fmt.Errorf("alphabet needs 1 to 256 symbols got %d", len(alphabet))
+	}
+	var seen [128]bool
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c >= 0x80 {
+			return fmt.Errorf("alphabet %q has a non ascii byte at %d", alphabet, i)
+		}
+		if seen[c] {
+			return fmt.Errorf("alphabet %q repeats %q", alphabet, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
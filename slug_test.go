@@ -0,0 +1,448 @@
+package namemachine
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/**
+ * TestSlugOnly checks slug only names are exactly SlugLength and ignore word settings
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugOnly(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"adjectives/*.txt"},
+		SlugOnly:     true,
+		SlugLength:   10,
+		Words:        3, // ignored in slug only mode
+		Seed:         1,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		name := g.Generate(2)
+		if len(name) != 10 || strings.Contains(name, "_") {
+			t.Fatalf("slug only name %q", name)
+		}
+		if _, slug, ok := Parse(name, Options{SlugOnly: true, SlugLength: 10}); !ok || slug != name {
+			t.Fatalf("Parse slug only %q got %q %v", name, slug, ok)
+		}
+	}
+
+	// with a counter the two suffixes are joined by the delimiter only
+	g.counterWidth = 2
+	g.perm = newFeistel(1<<10, 1)
+	if name := g.Generate(0); len(name) != 13 || name[10] != '_' {
+		t.Fatalf("slug and counter name %q", name)
+	}
+
+	if _, err := New(Options{SlugOnly: true}); err == nil {
+		t.Fatal("expected error for SlugOnly without a slug")
+	}
+}
+
+/**
+ * TestSlugAvoid checks avoided characters never appear and an emptied alphabet errors
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugAvoid(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"adjectives/*.txt"},
+		SlugOnly:     true,
+		SlugLength:   32,
+		SlugAvoid:    "lo25aeiu",
+		Seed:         1,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(g.slugAlphabet) != 32-8 {
+		t.Fatalf("alphabet size %d want 24", len(g.slugAlphabet))
+	}
+	for i := 0; i < 500; i++ {
+		if slug := g.Generate(0); strings.ContainsAny(slug, "lo25aeiu") {
+			t.Fatalf("slug %q contains an avoided character", slug)
+		}
+	}
+
+	if _, err := New(Options{SlugLength: 4, SlugAvoid: string(base32)}); err == nil {
+		t.Fatal("expected error when SlugAvoid empties the alphabet")
+	}
+}
+
+/**
+ * TestSeededSlugsReproducible checks the same seed replays the same slugs and different seeds diverge
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSeededSlugsReproducible(t *testing.T) {
+	run := func(seed int64) []string {
+		g, err := New(Options{
+			IncludeGlobs: []string{"adjectives/*.txt"},
+			SlugOnly:     true,
+			SlugLength:   12,
+			SeededSlugs:  true,
+			Seed:         seed,
+		})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		out := make([]string, 20)
+		for i := range out {
+			out[i] = g.Generate(0)
+		}
+		return out
+	}
+
+	a, b, c := run(42), run(42), run(43)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed diverged\n%v\n%v", a, b)
+	}
+	same := 0
+	for i := range a {
+		if a[i] == c[i] {
+			same++
+		}
+		for _, ch := range []byte(a[i]) {
+			if !(ch >= 'a' && ch <= 'z' || ch >= '2' && ch <= '7') {
+				t.Fatalf("slug %q outside base32", a[i])
+			}
+		}
+	}
+	if same > 0 {
+		t.Fatalf("different seeds shared %d slugs", same)
+	}
+}
+
+/**
+ * TestSlugGroupInsertsSeparators checks grouped slugs layout length and Parse round trip
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugGroupInsertsSeparators(t *testing.T) {
+	opts := Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, SlugLength: 8, SlugGroup: 3, Seed: 2}
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		name := g.Generate(0)
+		slug := name[strings.LastIndexByte(name, '_')+1:]
+		if len(slug) != 10 || slug[3] != '-' || slug[7] != '-' || strings.Count(slug, "-") != 2 {
+			t.Fatalf("bad grouped slug %q in %q", slug, name)
+		}
+		if _, got, ok := Parse(name, opts); !ok || got != slug {
+			t.Fatalf("Parse(%q) = %q %v", name, got, ok)
+		}
+	}
+
+	// exact multiples get no trailing separator
+	if got := string(groupSlug([]byte("x_abcdef"), 2, 3, '.')); got != "x_abc.def" {
+		t.Fatalf("groupSlug = %q", got)
+	}
+}
+
+/**
+ * TestRejectDegenerateSlugsRedraws forces an all zero first read which maps to aaaaaa
+ * and checks the slug is redrawn from the following read
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestRejectDegenerateSlugsRedraws(t *testing.T) {
+	reads := 0
+	src := readerFunc(func(b []byte) (int, error) {
+		reads++
+		for i := range b {
+			b[i] = 0
+			if reads > 1 {
+				b[i] = byte(i)
+			}
+		}
+		return len(b), nil
+	})
+
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, SlugLength: 6, RejectDegenerateSlugs: true, EntropySource: src, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	name := g.Generate(0)
+	if !strings.HasSuffix(name, "_abcdef") || reads != 2 {
+		t.Fatalf("expected a redraw to abcdef got %q after %d reads", name, reads)
+	}
+
+	for _, c := range []struct {
+		s    string
+		want bool
+	}{{"aaaaaa", true}, {"222222", true}, {"234567", true}, {"a22222", false}, {"a", false}} {
+		if got := isDegenerateSlug([]byte(c.s)); got != c.want {
+			t.Fatalf("isDegenerateSlug(%q) = %v", c.s, got)
+		}
+	}
+}
+
+/**
+ * readerFunc adapts a function to io.Reader for entropy tests
+ */
+type readerFunc func([]byte) (int, error)
+
+/**
+ * Read calls the function
+ * @param b []byte destination
+ * @return int bytes read and error
+ */
+func (f readerFunc) Read(b []byte) (int, error) {
+	return f(b)
+}
+
+/**
+ * failAfter is a reader that hands out k bytes of 'b' then fails
+ */
+type failAfter struct {
+	k int
+}
+
+/**
+ * Read serves the remaining budget then reports an error
+ * @param b []byte destination
+ * @return int bytes read and error once the budget is spent
+ */
+func (f *failAfter) Read(b []byte) (int, error) {
+	if f.k == 0 {
+		return 0, errors.New("entropy gone")
+	}
+	n := copy(b, bytes.Repeat([]byte{1}, f.k))
+	f.k -= n
+	return n, nil
+}
+
+/**
+ * TestEntropySourceFallback drives the slug from a reader that fails after K bytes
+ * and checks the remainder is filled per OnEntropyFailure
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestEntropySourceFallback(t *testing.T) {
+	for _, policy := range []EntropyFailurePolicy{EntropyFailFill, EntropyFailError} {
+		g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, SlugLength: 8, EntropySource: &failAfter{k: 3}, OnEntropyFailure: policy, Seed: 4})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		name, err := g.GenerateE(0)
+		if !strings.HasSuffix(name, "_bbbaaaaa") {
+			t.Fatalf("policy %d: slug should keep 3 drawn bytes then pad got %q", policy, name)
+		}
+		if policy == EntropyFailFill && err != nil {
+			t.Fatalf("fill policy reported %v", err)
+		}
+		if policy == EntropyFailError && !errors.Is(err, ErrEntropy) {
+			t.Fatalf("error policy got %v want ErrEntropy", err)
+		}
+	}
+
+	// a healthy custom source is used as is
+	g, err := New(Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, SlugLength: 4, EntropySource: bytes.NewReader(bytes.Repeat([]byte{2}, 64)), Seed: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if name := g.Generate(0); !strings.HasSuffix(name, "_cccc") {
+		t.Fatalf("custom source not used got %q", name)
+	}
+
+	// reads go through pooled scratch so a custom source costs no allocation per name
+	g, err = New(Options{IncludeGlobs: []string{"adjectives/*.txt"}, Words: 1, SlugLength: 8, EntropySource: rand.New(rand.NewSource(3)), Seed: 4})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(100, func() { buf = g.GenerateInto(buf[:0], 0) }); n != 0 && !raceEnabled {
+		t.Fatalf("GenerateInto with a custom entropy source allocated %v times", n)
+	}
+}
+
+/**
+ * TestSlugsPrefixAndSuffix checks extra slugs land at their positions with their lengths
+ * and that a roomy buffer still gives zero allocation builds
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugsPrefixAndSuffix(t *testing.T) {
+	g, err := New(Options{
+		IncludeGlobs: []string{"adjectives/*.txt", "nouns/*.txt"},
+		Strategy:     MergeByDir,
+		Words:        2,
+		Delimiter:    '-',
+		ASCIIOnly:    true,
+		Slugs: []SlugSpec{
+			{Length: 3, Position: SlugPrefix, Alphabet: "0123456789abcdef"},
+			{Length: 2, Position: SlugAfterWord, AfterWord: 0, Alphabet: "xyz"},
+			{Length: 4},
+		},
+		Seed: 12,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		name := g.Generate(0)
+		parts := strings.Split(name, "-")
+		if len(parts) != 5 {
+			t.Fatalf("want prefix word mid word suffix got %q", name)
+		}
+		if len(parts[0]) != 3 || strings.Trim(parts[0], "0123456789abcdef") != "" {
+			t.Fatalf("bad prefix slug in %q", name)
+		}
+		if len(parts[2]) != 2 || strings.Trim(parts[2], "xyz") != "" {
+			t.Fatalf("bad after word slug in %q", name)
+		}
+		if len(parts[4]) != 4 || strings.Trim(parts[4], string(base32)) != "" {
+			t.Fatalf("bad suffix slug in %q", name)
+		}
+		if !g.Contains(parts[1]) || !g.Contains(parts[3]) {
+			t.Fatalf("words misplaced in %q", name)
+		}
+	}
+	buf := make([]byte, 0, 128)
+	if n := testing.AllocsPerRun(50, func() { buf = g.GenerateInto(buf[:0], 0) }); n != 0 && !raceEnabled {
+		t.Fatalf("GenerateInto with extra slugs allocated %v times", n)
+	}
+
+	if _, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, Slugs: []SlugSpec{{Length: 0}}, Seed: 1}); err == nil {
+		t.Fatal("zero length slug spec should fail")
+	}
+	for _, alphabet := range []string{strings.Repeat("ab", 150), "äöü", "abca"} {
+		if _, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, Slugs: []SlugSpec{{Length: 3, Alphabet: alphabet}}, Seed: 1}); err == nil {
+			t.Fatalf("alphabet %q should fail", alphabet)
+		}
+	}
+}
+
+/**
+ * TestSlugNoVowelsKind checks no vowel reaches a slug under SlugNoVowels
+ * and that the 27 remaining symbols come up about equally often
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugNoVowelsKind(t *testing.T) {
+	g, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, SlugOnly: true, SlugLength: 16, SlugKind: SlugNoVowels, Seed: 2})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	counts := map[rune]int{}
+	const names = 2000
+	for i := 0; i < names; i++ {
+		slug := g.Generate(0)
+		if strings.ContainsAny(slug, "aeiou") {
+			t.Fatalf("slug %q contains a vowel", slug)
+		}
+		for _, c := range slug {
+			counts[c]++
+		}
+	}
+	if len(counts) != len(noVowels) {
+		t.Fatalf("saw %d symbols want %d", len(counts), len(noVowels))
+	}
+	want := float64(names*16) / float64(len(noVowels))
+	for c, n := range counts {
+		if math.Abs(float64(n)-want) > want*0.15 {
+			t.Fatalf("symbol %q drawn %d times want about %.0f", c, n, want)
+		}
+	}
+
+	if _, err := New(Options{IncludeGlobs: []string{"nouns/*.txt"}, SlugLength: 4, SlugKind: SlugKind(9), Seed: 2}); err == nil {
+		t.Fatal("unknown SlugKind should fail")
+	}
+}
+
+/**
+ * TestMaxSlugLengthRejectsHugeSlugs checks an over large SlugLength fails at New
+ * while a raised MaxSlugLength admits it
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestMaxSlugLengthRejectsHugeSlugs(t *testing.T) {
+	base := Options{IncludeGlobs: []string{"nouns/*.txt"}, Seed: 1}
+
+	opts := base
+	opts.SlugLength = 10_000_000
+	if _, err := New(opts); err == nil || !strings.Contains(err.Error(), "MaxSlugLength 256") {
+		t.Fatalf("huge SlugLength got %v", err)
+	}
+
+	opts = base
+	opts.Slugs = []SlugSpec{{Length: 300}}
+	if _, err := New(opts); err == nil {
+		t.Fatal("huge Slugs length should fail")
+	}
+
+	opts = base
+	opts.SlugLength, opts.MaxSlugLength = 300, 512
+	g, err := New(opts)
+	if err != nil {
+		t.Fatalf("raised cap: %v", err)
+	}
+	if _, slug, _ := strings.Cut(g.Generate(1), "_"); len(slug) != 300 {
+		t.Fatalf("slug length %d want 300", len(slug))
+	}
+}
+
+/**
+ * TestSlugDecimalAlphabetIsUniform checks a length 10 alphabet has no modulo bias
+ * a seeded byte source keeps the chi square deterministic and the same bytes mapped
+ * by plain modulo must fail so the test can tell the two apart
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestSlugDecimalAlphabetIsUniform(t *testing.T) {
+	const n = 1_000_000
+	const critical = 27.88 // chi square with 9 degrees of freedom at p = 0.001
+	alphabet := []byte("0123456789")
+
+	var raw bytes.Buffer
+	ent := entropyBuf{src: io.TeeReader(rand.New(rand.NewSource(11)), &raw)}
+	out := ent.appendSlug(make([]byte, 0, n), n, alphabet)
+	if len(out) != n {
+		t.Fatalf("slug length %d want %d", len(out), n)
+	}
+
+	chi := func(counts [10]int, total int) float64 {
+		want := float64(total) / 10
+		sum := 0.0
+		for _, c := range counts {
+			d := float64(c) - want
+			sum += d * d / want
+		}
+		return sum
+	}
+	var got, naive [10]int
+	for _, b := range out {
+		got[b-'0']++
+	}
+	for _, b := range raw.Bytes()[:n] {
+		naive[int(b)%10]++
+	}
+	if x := chi(got, n); x > critical {
+		t.Fatalf("rejection sampled chi square %.1f above %.2f counts %v", x, critical, got)
+	}
+	if x := chi(naive, n); x <= critical {
+		t.Fatalf("plain modulo chi square %.1f should show the bias", x)
+	}
+
+	// base32 divides 256 so every byte is used and one buffered read covers 64 symbols
+	ent = entropyBuf{src: rand.New(rand.NewSource(11))}
+	if out := ent.appendSlug(nil, 64, base32); len(out) != 64 || ent.reads != 1 {
+		t.Fatalf("base32 slug took %d reads for %d symbols want 1", ent.reads, len(out))
+	}
+	buf := make([]byte, 0, 16)
+	if allocs := testing.AllocsPerRun(100, func() { buf = appendSlug(buf[:0], 16, alphabet) }); allocs != 0 && !raceEnabled {
+		t.Fatalf("decimal slug allocated %v times", allocs)
+	}
+}
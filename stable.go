@@ -0,0 +1,51 @@
+package namemachine
+
+import "math/rand"
+
+/**
+ * splitmix64 is the stable random source behind Options.StableRandom
+ * a fixed vendored algorithm so a seed maps to the same stream on every Go
+ * release unlike the unexported math/rand source which carries no such promise
+ */
+type splitmix64 struct {
+	s uint64
+}
+
+/**
+ * Seed resets the stream to seed
+ * @param seed int64 seed value
+ * @return void
+ */
+func (m *splitmix64) Seed(seed int64) {
+	m.s = uint64(seed)
+}
+
+/**
+ * Uint64 advances the state by the golden gamma and mixes it
+ * @return uint64 next value
+ */
+func (m *splitmix64) Uint64() uint64 {
+	m.s += 0x9E3779B97F4A7C15
+	return mix64(m.s)
+}
+
+/**
+ * Int63 returns the top 63 bits of the next value
+ * @return int64 non negative value
+ */
+func (m *splitmix64) Int63() int64 {
+	return int64(m.Uint64() >> 1)
+}
+
+/**
+ * newRand returns a rand.Rand over the stable source or the math/rand one
+ * @param seed int64 seed value
+ * @param stable bool use splitmix64
+ * @return *rand.Rand seeded generator
+ */
+func newRand(seed int64, stable bool) *rand.Rand {
+	if stable {
+		return rand.New(&splitmix64{s: uint64(seed)})
+	}
+	return rand.New(rand.NewSource(seed))
+}
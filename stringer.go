@@ -0,0 +1,110 @@
+package namemachine
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+/**
+ * String summarizes the generator settings for logs without dumping word lists
+ * for example Generator{lists:3, words:2, delim:'_', slug:6}
+ * @return string concise summary
+ */
+func (g *Generator) String() string {
+	var buf [96]byte
+	return string(g.appendSummary(buf[:0]))
+}
+
+/**
+ * GoString gives %#v the same summary qualified with the package name
+ * @return string concise summary
+ */
+func (g *Generator) GoString() string {
+	var buf [112]byte
+	return string(g.appendSummary(append(buf[:0], "namemachine."...)))
+}
+
+/**
+ * Format lets a generator be printed directly
+ * %s emits a freshly generated name on every call so it consumes the stream
+ * just like Generate(0) and %q emits one quoted %v prints the String summary
+ * %+v adds the list ids and %#v prints GoString none of them reveal words
+ * @param f fmt.State formatter state
+ * @param verb rune format verb
+ * @return void
+ */
+func (g *Generator) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		io.WriteString(f, g.Generate(0))
+	case 'q':
+		io.WriteString(f, strconv.Quote(g.Generate(0)))
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			io.WriteString(f, g.GoString())
+		case f.Flag('+') && g != nil:
+			s := g.String()
+			fmt.Fprintf(f, "%s, ids:%v}", s[:len(s)-1], g.ids)
+		default:
+			io.WriteString(f, g.String())
+		}
+	default:
+		fmt.Fprintf(f, "%%!%c(*namemachine.Generator)", verb)
+	}
+}
+
+/**
+ * appendSummary writes the summary into dst
+ * word settings show the exact count the allowed set or the min max range
+ * @param dst []byte destination buffer
+ * @return []byte buffer with the summary appended
+ */
+func (g *Generator) appendSummary(dst []byte) []byte {
+	if g == nil {
+		return append(dst, "Generator(nil)"...)
+	}
+	dst = append(dst, "Generator{lists:"...)
+	dst = strconv.AppendInt(dst, int64(len(g.lists)), 10)
+
+	dst = append(dst, ", words:"...)
+	g.lock()
+	exact, min, max, allowed := g.wordsExact, g.minWords, g.maxWords, g.allowedCounts
+	g.unlock()
+	switch {
+	case g.slugOnly:
+		dst = append(dst, '0')
+	case exact > 0:
+		dst = strconv.AppendInt(dst, int64(exact), 10)
+	case len(allowed) > 0:
+		for i, n := range allowed {
+			if i > 0 {
+				dst = append(dst, '|')
+			}
+			dst = strconv.AppendInt(dst, int64(n), 10)
+		}
+	case min <= 0 && max <= 0:
+		dst = append(dst, '2')
+	default:
+		dst = strconv.AppendInt(dst, int64(min), 10)
+		dst = append(dst, ".."...)
+		dst = strconv.AppendInt(dst, int64(max), 10)
+	}
+
+	dst = append(dst, ", delim:"...)
+	if len(g.delim) == 1 {
+		dst = strconv.AppendQuoteRune(dst, rune(g.delim[0]))
+	} else {
+		dst = strconv.AppendQuote(dst, string(g.delim))
+	}
+	if g.slugLen > 0 {
+		dst = append(dst, ", slug:"...)
+		dst = strconv.AppendInt(dst, int64(g.slugLen), 10)
+	}
+	if g.counterWidth > 0 {
+		dst = append(dst, ", counter:"...)
+		dst = strconv.AppendInt(dst, int64(g.counterWidth), 10)
+	}
+	return append(dst, '}')
+}
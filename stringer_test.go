@@ -0,0 +1,67 @@
+package namemachine
+
+import (
+	"fmt"
+	"testing"
+)
+
+/**
+ * TestGeneratorStringSummary checks String reports list count and word settings
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGeneratorStringSummary(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave"}, "b/y.txt": {"otter"}, "c/z.txt": {"heron"}}
+	g, err := NewFromFiles(files, Options{Strategy: MergeByFile, Words: 2, SlugLength: 6, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got, want := g.String(), "Generator{lists:3, words:2, delim:'_', slug:6}"; got != want {
+		t.Fatalf("String() = %s want %s", got, want)
+	}
+
+	g, err = NewFromFiles(files, Options{MinWords: 2, MaxWords: 4, Delimiter: '-', Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := fmt.Sprintf("%#v", g); got != "namemachine.Generator{lists:3, words:2..4, delim:'-'}" {
+		t.Fatalf("GoString() = %s", got)
+	}
+}
+
+/**
+ * TestGeneratorFormatVerbs checks %s and %q generate names and %v %+v %#v print summaries
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestGeneratorFormatVerbs(t *testing.T) {
+	files := map[string][]string{"a/x.txt": {"brave"}, "b/y.txt": {"otter"}}
+	g, err := NewFromFiles(files, Options{Strategy: MergeByFile, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := fmt.Sprintf("%s", g); got != "brave_otter" {
+		t.Fatalf("%%s = %q", got)
+	}
+	if got := fmt.Sprintf("%q", g); got != `"brave_otter"` {
+		t.Fatalf("%%q = %q", got)
+	}
+	if g.Position() != 2 {
+		t.Fatalf("expected two generations got %d", g.Position())
+	}
+	if got := fmt.Sprintf("%v", g); got != "Generator{lists:2, words:2, delim:'_'}" {
+		t.Fatalf("%%v = %q", got)
+	}
+	if got := fmt.Sprintf("%+v", g); got != "Generator{lists:2, words:2, delim:'_', ids:[a/x.txt b/y.txt]}" {
+		t.Fatalf("%%+v = %q", got)
+	}
+	if got := fmt.Sprintf("%#v", g); got != "namemachine.Generator{lists:2, words:2, delim:'_'}" {
+		t.Fatalf("%%#v = %q", got)
+	}
+	if got := fmt.Sprintf("%d", g); got != "%!d(*namemachine.Generator)" {
+		t.Fatalf("%%d = %q", got)
+	}
+	if g.Position() != 2 {
+		t.Fatal("summary verbs must not generate")
+	}
+}
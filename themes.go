@@ -0,0 +1,91 @@
+package namemachine
+
+import (
+	"fmt"
+	"sort"
+)
+
+/**
+ * themes maps a theme name to the include globs it selects
+ * each theme pairs a few adjective files with nouns on the same topic
+ */
+var themes = map[string][]string{
+	"space": {
+		"adjectives/physics.txt",
+		"adjectives/temperature.txt",
+		"nouns/astronomy.txt",
+		"nouns/physics.txt",
+	},
+	"ocean": {
+		"adjectives/colors.txt",
+		"adjectives/weather.txt",
+		"nouns/fish.txt",
+		"nouns/water.txt",
+		"nouns/military_navy.txt",
+	},
+	"food": {
+		"adjectives/food.txt",
+		"adjectives/taste.txt",
+		"nouns/cheese.txt",
+		"nouns/condiments.txt",
+		"nouns/fruit.txt",
+		"nouns/meat.txt",
+		"nouns/seasonings.txt",
+	},
+	"music": {
+		"adjectives/music_theory.txt",
+		"adjectives/sound.txt",
+		"nouns/music_instruments.txt",
+		"nouns/music_theory.txt",
+	},
+	"animals": {
+		"adjectives/character.txt",
+		"adjectives/speed.txt",
+		"nouns/apex_predators.txt",
+		"nouns/birds.txt",
+		"nouns/cats.txt",
+		"nouns/dogs.txt",
+		"nouns/monkeys.txt",
+		"nouns/snakes.txt",
+	},
+	"software": {
+		"adjectives/algorithms.txt",
+		"adjectives/complexity.txt",
+		"nouns/coding.txt",
+		"nouns/data_structures.txt",
+		"nouns/software.txt",
+	},
+}
+
+/**
+ * Themes lists the names accepted by Options.Theme in sorted order
+ * @return []string theme names
+ */
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * themeGlobs adds the globs for theme to the explicit include globs
+ * explicit globs are kept first so a theme only widens the selection
+ * @param theme string theme name empty means none
+ * @param includes []string explicit include globs
+ * @return []string combined globs and error for an unknown theme
+ */
+func themeGlobs(theme string, includes []string) ([]string, error) {
+	if theme == "" {
+		return includes, nil
+	}
+	globs, ok := themes[theme]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (have %v)", theme, Themes())
+	}
+	out := make([]string, 0, len(includes)+len(globs))
+	out = append(out, includes...)
+	return append(out, globs...), nil
+}
@@ -0,0 +1,34 @@
+package namemachine
+
+import (
+	"strings"
+	"testing"
+)
+
+/**
+ * TestThemeSelectsCuratedLists checks a theme selects its files and unknown themes error
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestThemeSelectsCuratedLists(t *testing.T) {
+	g, err := New(Options{Theme: "ocean", Strategy: MergeByFile, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(g.lists) != len(themes["ocean"]) {
+		t.Fatalf("expected %d lists got %d", len(themes["ocean"]), len(g.lists))
+	}
+
+	// explicit globs compose with the theme
+	g, err = New(Options{Theme: "ocean", IncludeGlobs: []string{"verbs/movement.txt"}, Strategy: MergeByFile, Seed: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(g.lists) != len(themes["ocean"])+1 {
+		t.Fatalf("expected theme plus explicit glob got %d lists", len(g.lists))
+	}
+
+	if _, err := New(Options{Theme: "nope"}); err == nil || !strings.Contains(err.Error(), "unknown theme") {
+		t.Fatalf("expected unknown theme error got %v", err)
+	}
+}
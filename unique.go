@@ -0,0 +1,153 @@
+package namemachine
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+)
+
+/**
+ * UniqueStream hands out every name of one word count exactly once in a random
+ * looking order walking the NameAt space through a keyed feistel permutation
+ * its position lives in a CounterStore so a restarted service resumes the same
+ * order without repeats a block is reserved ahead of each Next so a crash skips
+ * at most one block while Save records the exact position for a clean shutdown
+ * the order is keyed by Seed so it must be fixed and MaxTotalLen truncation can
+ * merge names so leave it unset safe for concurrent use
+ */
+type UniqueStream struct {
+	g      *Generator
+	nWords int
+	perm   *feistel
+	store  CounterStore
+
+	mu       sync.Mutex
+	next     uint64 // position of the next name in the permuted order
+	reserved uint64 // first position not yet covered by a Save
+}
+
+/**
+ * UniqueStream opens a non repeating stream over the names of nWords words
+ * spaces past 2^64 names are walked over their first 2^64 - 1 indices
+ * @param nWords int word count zero uses Words
+ * @param store CounterStore persists the position nil keeps it in memory only
+ * @return *UniqueStream stream resumed from store and error when it cannot be loaded
+ */
+func (g *Generator) UniqueStream(nWords int, store CounterStore) (*UniqueStream, error) {
+	if len(g.lists) == 0 {
+		return nil, ErrNoLists
+	}
+	count := g.enumWords(nWords)
+	if count <= 0 {
+		return nil, fmt.Errorf("UniqueStream needs nWords or Words greater than zero")
+	}
+	limit := uint64(math.MaxUint64)
+	if total := g.Combinations(count); total.IsUint64() {
+		limit = total.Uint64()
+	}
+	s := &UniqueStream{
+		g:      g,
+		nWords: count,
+		perm:   newFeistel(limit, uint64(g.seed)^mix64(uint64(count))),
+		store:  store,
+	}
+	if err := s.Restore(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/**
+ * Next returns the next unused name skipping any that ReservedWords reject
+ * or Reserve holds the same rule GenerateIndices applies
+ * @return string name and ErrExhausted once every name has been handed out
+ */
+func (s *UniqueStream) Next() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var index big.Int
+	for s.next < s.perm.limit {
+		if err := s.reserve(); err != nil {
+			return "", err
+		}
+		index.SetUint64(s.perm.permute(s.next))
+		name, err := s.g.NameAt(&index, s.nWords)
+		if err != nil {
+			return "", err
+		}
+		s.next++
+		if b := []byte(name); !s.g.isHeld(b) && !s.g.isReserved(b) {
+			return name, nil
+		}
+	}
+	return "", ErrExhausted
+}
+
+/**
+ * reserve saves a block ahead before position next is handed out caller holds mu
+ * @return error if the store rejects the save
+ */
+func (s *UniqueStream) reserve() error {
+	if s.store == nil || s.next < s.reserved {
+		return nil
+	}
+	ahead := s.next + defaultCounterSaveEvery
+	if ahead < s.next || ahead > s.perm.limit {
+		ahead = s.perm.limit
+	}
+	if err := s.store.Save(ahead); err != nil {
+		return fmt.Errorf("save counter: %w", err)
+	}
+	s.reserved = ahead
+	return nil
+}
+
+/**
+ * Save records the exact position so the next Restore continues with the name
+ * Next would have returned call it on a clean shutdown
+ * @return error if the store rejects the save
+ */
+func (s *UniqueStream) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.Save(s.next); err != nil {
+		return fmt.Errorf("save counter: %w", err)
+	}
+	s.reserved = s.next
+	return nil
+}
+
+/**
+ * Restore moves the stream back to the position the store last saved
+ * @return error if the store cannot be loaded
+ */
+func (s *UniqueStream) Restore() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		return nil
+	}
+	next, err := s.store.Load()
+	if err != nil {
+		return fmt.Errorf("load counter: %w", err)
+	}
+	s.next, s.reserved = next, next
+	return nil
+}
+
+/**
+ * Remaining counts the names the stream has not yet reached
+ * @return uint64 positions left including any ReservedWords would skip
+ */
+func (s *UniqueStream) Remaining() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next >= s.perm.limit {
+		return 0
+	}
+	return s.perm.limit - s.next
+}
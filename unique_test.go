@@ -0,0 +1,81 @@
+package namemachine
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+/**
+ * TestUniqueStreamResumes restarts a UniqueStream mid stream from a shared store
+ * once cleanly and once after a crash and checks every name of the space comes
+ * out at most once before the stream reports exhaustion
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestUniqueStreamResumes(t *testing.T) {
+	files := map[string][]string{"a.txt": {"brave", "happy", "eager", "calm", "bold"}, "b.txt": {"otter", "fox", "newt", "owl"}}
+	open := func(store CounterStore) *UniqueStream {
+		g, err := NewFromFiles(files, Options{Words: 2, Seed: 21})
+		if err != nil {
+			t.Fatalf("NewFromFiles: %v", err)
+		}
+		s, err := g.UniqueStream(0, store)
+		if err != nil {
+			t.Fatalf("UniqueStream: %v", err)
+		}
+		return s
+	}
+	store := &memCounterStore{}
+	seen := make(map[string]bool)
+	take := func(s *UniqueStream, n int) {
+		for i := 0; i < n; i++ {
+			name, err := s.Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if seen[name] {
+				t.Fatalf("%q repeated", name)
+			}
+			seen[name] = true
+		}
+	}
+
+	// a clean shutdown resumes exactly where the stream left off
+	s := open(store)
+	take(s, 7)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	s = open(store)
+	if s.Remaining() != 13 {
+		t.Fatalf("Remaining = %d want 13", s.Remaining())
+	}
+	take(s, 13)
+	if _, err := s.Next(); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected ErrExhausted got %v", err)
+	}
+	if len(seen) != 20 {
+		t.Fatalf("saw %d of 20 names", len(seen))
+	}
+
+	// the order is permuted rather than a walk of NameAt
+	fresh, inOrder := open(&memCounterStore{}), 0
+	for i := int64(0); i < 20; i++ {
+		name, _ := fresh.Next()
+		if at, _ := fresh.g.NameAt(big.NewInt(i), 2); at == name {
+			inOrder++
+		}
+	}
+	if inOrder > 5 {
+		t.Fatalf("%d of 20 names in NameAt order", inOrder)
+	}
+
+	// a crash without Save skips ahead to the reserved block instead of repeating
+	crash := &memCounterStore{}
+	seen = make(map[string]bool)
+	take(open(crash), 3)
+	if _, err := open(crash).Next(); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected the reserved block to cover the space got %v", err)
+	}
+}
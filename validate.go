@@ -0,0 +1,122 @@
+package namemachine
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+/**
+ * LintRule names a corpus check reported by ValidateLists
+ */
+type LintRule string
+
+const (
+	RuleEmptyFile  LintRule = "empty-file"  // file parsed but yielded no words
+	RuleBlankToken LintRule = "blank-token" // token is only whitespace
+	RuleNonAlnum   LintRule = "non-alnum"   // token has bytes outside ascii letters and digits
+	RuleDuplicate  LintRule = "duplicate"   // token repeats within the same file
+)
+
+/**
+ * LintIssue is one structured finding from ValidateLists
+ * file is relative to the validated root and line is one based
+ */
+type LintIssue struct {
+	File    string
+	Line    int
+	Rule    LintRule
+	Message string
+}
+
+/**
+ * ValidateLists runs the corpus checks used for the bundled lists over any fs
+ * every txt file under root is parsed like the loader and checked for
+ * no words blank tokens non alphanumeric tokens and in file duplicates
+ * issues are sorted by file then line so ci output is stable
+ * @param fsys fs.FS filesystem holding the lists
+ * @param root string directory inside fsys to walk use "." for the top
+ * @return []LintIssue findings and error when the walk or a read fails
+ */
+func ValidateLists(fsys fs.FS, root string) ([]LintIssue, error) {
+	var issues []LintIssue
+
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".txt" {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(p, root+"/")
+		issues = append(issues, lintFile(rel, b)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+/**
+ * lintFile checks the tokens of a single file
+ * @param name string file name used in issues
+ * @param b []byte file contents
+ * @return []LintIssue findings for this file
+ */
+func lintFile(name string, b []byte) []LintIssue {
+	var issues []LintIssue
+	seen := make(map[string]int)
+	count := 0
+
+	scanWordLines(b, Options{}, func(line int, w string) {
+		count++
+		switch {
+		case strings.TrimSpace(w) == "":
+			issues = append(issues, LintIssue{File: name, Line: line, Rule: RuleBlankToken,
+				Message: "token is blank"})
+			return
+		case !isAlnumASCII(w):
+			issues = append(issues, LintIssue{File: name, Line: line, Rule: RuleNonAlnum,
+				Message: fmt.Sprintf("token %q is not ascii alphanumeric", w)})
+		}
+		if first, ok := seen[w]; ok {
+			issues = append(issues, LintIssue{File: name, Line: line, Rule: RuleDuplicate,
+				Message: fmt.Sprintf("token %q duplicates line %d", w, first)})
+			return
+		}
+		seen[w] = line
+	})
+
+	if count == 0 {
+		issues = append(issues, LintIssue{File: name, Rule: RuleEmptyFile, Message: "file has no words"})
+	}
+	return issues
+}
+
+/**
+ * isAlnumASCII reports whether the input contains only ascii digits or letters
+ * digits zero to nine and letters a to z or A to Z
+ * @param s string input token
+ * @return bool true when token is ascii alphanumeric only
+ */
+func isAlnumASCII(s string) bool {
+	// scan byte by byte and reject on first non alnum
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if !((b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package namemachine
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+/**
+ * TestValidateListsBrokenCorpus feeds a deliberately broken MapFS and checks each rule fires
+ * with the right file and line
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestValidateListsBrokenCorpus(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lists/good/ok.txt":    {Data: []byte("alpha\nbeta\n")},
+		"lists/bad/empty.txt":  {Data: []byte("# only a comment\n\n")},
+		"lists/bad/mixed.txt":  {Data: []byte("alpha\nco-op\nalpha\n\"  \"\n")},
+		"lists/bad/ignore.csv": {Data: []byte("not,a,list\n")},
+	}
+
+	issues, err := ValidateLists(fsys, "lists")
+	if err != nil {
+		t.Fatalf("ValidateLists: %v", err)
+	}
+
+	type key struct {
+		file string
+		line int
+		rule LintRule
+	}
+	want := []key{
+		{"bad/empty.txt", 0, RuleEmptyFile},
+		{"bad/mixed.txt", 2, RuleNonAlnum},
+		{"bad/mixed.txt", 3, RuleDuplicate},
+		{"bad/mixed.txt", 4, RuleBlankToken},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues want %d: %+v", len(issues), len(want), issues)
+	}
+	for i, w := range want {
+		got := key{issues[i].File, issues[i].Line, issues[i].Rule}
+		if got != w {
+			t.Fatalf("issue %d got %+v want %+v", i, got, w)
+		}
+		if issues[i].Message == "" {
+			t.Fatalf("issue %d has no message", i)
+		}
+	}
+}
+
+/**
+ * TestValidateListsEmbeddedClean runs the validator over the bundled corpus
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestValidateListsEmbeddedClean(t *testing.T) {
+	issues, err := ValidateLists(listsFS, "lists")
+	if err != nil {
+		t.Fatalf("ValidateLists: %v", err)
+	}
+	for i, is := range issues {
+		if i == 20 {
+			t.Logf("...and %d more", len(issues)-i)
+			break
+		}
+		t.Logf("%s:%d %s %s", is.File, is.Line, is.Rule, is.Message)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("bundled lists have %d lint issues", len(issues))
+	}
+}
+
+/**
+ * TestValidateListsMissingRoot surfaces walk errors instead of an empty report
+ * @param t *testing.T test harness
+ * @return void
+ */
+func TestValidateListsMissingRoot(t *testing.T) {
+	if _, err := ValidateLists(fstest.MapFS{}, "nope"); err == nil {
+		t.Fatal("expected error for missing root")
+	}
+}